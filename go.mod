@@ -3,6 +3,7 @@ module github.com/hashicorp/actions-go-build
 go 1.24.0
 
 require (
+	github.com/go-git/go-git/v5 v5.16.2
 	github.com/google/go-cmp v0.7.0
 	github.com/hashicorp/composite-action-framework-go v0.1.0
 	github.com/hashicorp/go-version v1.7.0
@@ -10,6 +11,7 @@ require (
 	github.com/otiai10/copy v1.14.1
 	github.com/sethvargo/go-envconfig v1.3.0
 	github.com/sethvargo/go-githubactions v1.3.1
+	github.com/ulikunitz/xz v0.5.15
 	golang.org/x/mod v0.29.0
 	golang.org/x/term v0.36.0
 )
@@ -29,7 +31,6 @@ require (
 	github.com/fatih/color v1.7.0 // indirect
 	github.com/go-git/gcfg v1.5.1-0.20230307220236-3a3c6141e376 // indirect
 	github.com/go-git/go-billy/v5 v5.6.2 // indirect
-	github.com/go-git/go-git/v5 v5.16.2 // indirect
 	github.com/golang/groupcache v0.0.0-20241129210726-2c02b8208cf8 // indirect
 	github.com/google/uuid v1.1.2 // indirect
 	github.com/hashicorp/errwrap v1.0.0 // indirect
@@ -0,0 +1,35 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+// Package atomicfile replaces files so that a crash never leaves a partly
+// written one behind.
+package atomicfile
+
+import (
+	"os"
+
+	"github.com/hashicorp/composite-action-framework-go/pkg/json"
+)
+
+// WriteJSON writes v to path as indented JSON. It's written to a temporary
+// file, which is synced to disk before being renamed over path, so path
+// always holds either the old or the new contents.
+func WriteJSON(path string, v any) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := json.Write(f, v); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
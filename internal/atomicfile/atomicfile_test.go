@@ -0,0 +1,34 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package atomicfile
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/composite-action-framework-go/pkg/json"
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+func TestWriteJSON(t *testing.T) {
+	path := filepath.Join(tmp.Dir(t), "state.json")
+	for _, want := range []string{"first", "second"} {
+		if err := WriteJSON(path, map[string]string{"Value": want}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.ReadFile[map[string]string](path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got["Value"] != want {
+			t.Errorf("got %q; want %q", got["Value"], want)
+		}
+	}
+	if _, err := os.Stat(path + ".tmp"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
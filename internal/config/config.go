@@ -4,18 +4,22 @@
 package config
 
 import (
-	"context"
 	"fmt"
+	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/hashicorp/actions-go-build/pkg/build"
 	"github.com/hashicorp/actions-go-build/pkg/crt"
 	"github.com/hashicorp/actions-go-build/pkg/digest"
-	"github.com/sethvargo/go-envconfig"
 )
 
 // ConfigIDFunc can be overridden in tests to provide a stable ID.
-var ConfigIDFunc = func(c Config) string { return digest.ID(c) }
+var ConfigIDFunc = func(c Config) string {
+	// As for build.Config.ID, the source tree hash is left out.
+	c.Product.SourceTreeHash = ""
+	return digest.ID(c)
+}
 
 // Config represents the action configuration.
 type Config struct {
@@ -46,14 +50,23 @@ type Config struct {
 	// TargetDir can be used to override the primary target dir.
 	TargetDir string `env:"TARGET_DIR"`
 
+	// VersionStrategy determines how the core version is found when
+	// PRODUCT_VERSION isn't set. See crt.ParseVersionStrategy.
+	VersionStrategy string `env:"VERSION_STRATEGY"`
+
 	Primary      Paths `env:",prefix=PRIMARY_"`
 	Verification Paths `env:",prefix=VERIFICATION_"`
 
 	VerificationResult string `env:"VERIFICATION_RESULT"`
+
+	// Sources records which layer each value read by name came from, keyed
+	// by environment variable name. See Source.
+	Sources map[string]string `json:"-"`
 }
 
 type Paths struct {
-	// BuildRoot is the absolute path where the instructions are run for this build.
+	// BuildRoot is the absolute path where the instructions are run for this build,
+	// or for products declared in a ProductsFile, the root of the repository copy.
 	// We read it from the environment only to support testing.
 	BuildRoot string `env:"BUILD_ROOT"`
 	// TargetDir can be used to overwrite the target output directory.
@@ -64,16 +77,66 @@ type Paths struct {
 	BuildResult string
 }
 
-// FromEnvironment creates a new Config from environment variables
-// and repository context in the current working directory.
+// FromEnvironment creates a new Config from the FileName in dir,
+// environment variables, and repository context in dir, in that order.
 func FromEnvironment(creator crt.Tool, dir string) (Config, error) {
-	var c Config
-	ctx := context.Background()
-	if err := envconfig.Process(ctx, &c); err != nil {
+	c, err := load(dir)
+	if err != nil {
 		return c, err
 	}
+	return c.fromRepo(creator, dir)
+}
 
-	rc, err := crt.GetRepoContext(dir, build.Dirs.List())
+// FromEnvironmentForProduct is like FromEnvironment, but for the product p
+// declared in the ProductsFile in root. The fields set in p override the
+// environment and the FileName in root. The whole repository is built, so
+// changes anywhere in it make p dirty.
+func FromEnvironmentForProduct(creator crt.Tool, root string, p ProductSpec) (Config, error) {
+	c, err := load(root)
+	if err != nil {
+		return c, err
+	}
+	for name, o := range map[string]struct {
+		dst   *string
+		value string
+	}{
+		"PRODUCT_NAME":     {&c.Product.Name, p.Name},
+		"VERSION_STRATEGY": {&c.VersionStrategy, p.VersionStrategy},
+		"INSTRUCTIONS":     {&c.Parameters.Instructions, p.Instructions},
+	} {
+		if o.value != "" {
+			*o.dst = o.value
+			c.Sources[name] = SourceProducts
+		}
+	}
+	// The whole repository is built, with the instructions run in p.Dir.
+	c.Parameters.ProductDir = filepath.ToSlash(p.Dir)
+	if c.Primary.BuildRoot == "" {
+		c.Primary.BuildRoot = root
+	}
+	// Other products' build output doesn't make p dirty.
+	dirs := []string{path.Clean(filepath.ToSlash(p.Dir))}
+	others, err := LoadProducts(root)
+	if err != nil {
+		return c, err
+	}
+	for _, o := range others {
+		dirs = append(dirs, path.Clean(filepath.ToSlash(o.Dir)))
+	}
+	return c.fromRepo(creator, filepath.Join(root, filepath.FromSlash(p.Dir)), crt.WithProductDirs(dirs...))
+}
+
+func (c Config) fromRepo(creator crt.Tool, dir string, opts ...crt.RepoContextOption) (Config, error) {
+	vs, err := crt.ParseVersionStrategy(c.VersionStrategy)
+	if err != nil {
+		return c, err
+	}
+
+	opts = append(opts, crt.WithVersionStrategy(vs))
+	if strings.TrimSpace(c.Product.Version.Full) != "" {
+		opts = append(opts, crt.WithoutCoreVersion())
+	}
+	rc, err := crt.GetRepoContext(dir, build.Dirs.List(), opts...)
 	if err != nil {
 		return c, err
 	}
@@ -84,10 +147,14 @@ func FromEnvironment(creator crt.Tool, dir string) (Config, error) {
 // buildConfig returns a BuildConfig based on this Config, rooted at root.
 // The root must be an absolute path.
 func (c Config) buildConfig(root string) (build.Config, error) {
+	workDir, err := c.Parameters.WorkDir(root)
+	if err != nil {
+		return build.Config{}, err
+	}
 	// If c.TargetDir is empty here then this option is a no-op,
 	// we just pass it through rather than wrapping in a conditional.
 	opts := build.WithTargetDir(c.TargetDir)
-	paths, err := build.NewBuildPaths(root, c.Product.ExecutableName, c.Parameters.ZipName, opts)
+	paths, err := build.NewBuildPaths(workDir, c.Product.ExecutableName, c.Parameters.ZipName, opts)
 	if err != nil {
 		return build.Config{}, err
 	}
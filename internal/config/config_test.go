@@ -81,6 +81,18 @@ func TestConfig_init_ok(t *testing.T) {
 				c.VerificationResult = "/test/temp/dir/<tool-name>/<tool-version>/<tool-revision>/verification/<compound-cache-key>/cache/verificationresult/dadgarcorp/lockbox/lockbox/<source-hash>/<config-id>/blarglefish.zip.json"
 			}),
 		},
+		{
+			"archive format tar.gz",
+			testUninitializedConfig(func(i *Config) {
+				i.Parameters.ArchiveFormat = "tar.gz"
+			}),
+			testRepoContext(),
+			testConfig(func(c *Config) {
+				c.Parameters.ArchiveFormat = "tar.gz"
+				c.Parameters.ZipName = "lockbox_1.2.3_linux_amd64.tar.gz"
+				c.VerificationResult = "/test/temp/dir/<tool-name>/<tool-version>/<tool-revision>/verification/<compound-cache-key>/cache/verificationresult/dadgarcorp/lockbox/lockbox/<source-hash>/<config-id>/lockbox_1.2.3_linux_amd64.tar.gz.json"
+			}),
+		},
 		{
 			"overridden bin_name",
 			testUninitializedConfig(func(i *Config) {
@@ -8,6 +8,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/sethvargo/go-githubactions"
 )
@@ -60,6 +61,8 @@ func (c Config) EnvVars() ([]EnvVar, error) {
 	addEnv("INSTRUCTIONS", c.Parameters.Instructions)
 	addEnv("BIN_NAME", c.Product.ExecutableName)
 	addEnv("ZIP_NAME", c.Parameters.ZipName)
+	addEnv("ARCHIVE_FORMAT", c.Parameters.Archive())
+	addEnv("PRESERVE_PATHS", strconv.FormatBool(c.Parameters.PreservePaths))
 	addEnv("PRIMARY_BUILD_ROOT", c.Primary.BuildRoot)
 	addEnv("VERIFICATION_BUILD_ROOT", c.Verification.BuildRoot)
 	addEnv("PRIMARY_BUILD_RESULT", c.Primary.BuildResult)
@@ -71,6 +74,9 @@ func (c Config) EnvVars() ([]EnvVar, error) {
 	addEnv("VERIFICATION_RESULT", c.VerificationResult)
 	addEnv("DEBUG", strconv.FormatBool(c.Debug))
 	addEnv("TARGET_DIR", primary.Paths.TargetDir())
+	if len(c.Parameters.EnvAllowlist) != 0 {
+		addEnv("ENV_ALLOWLIST", strings.Join(c.Parameters.EnvAllowlist, ","))
+	}
 
 	return kvs, nil
 }
@@ -0,0 +1,112 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Export formats, for Config.Export.
+const (
+	// FormatPlain writes NAME=value lines, with values unquoted. It's only
+	// meant for people to read.
+	FormatPlain = "plain"
+	// FormatShell writes export statements for sh-compatible shells.
+	FormatShell = "shell"
+	// FormatDotenv writes a .env file, as read by docker compose and the
+	// dotenv libraries.
+	FormatDotenv = "dotenv"
+	// FormatJSON writes a JSON object mapping names to values.
+	FormatJSON = "json"
+	// FormatGitHubOutput writes in the format of the GITHUB_OUTPUT and
+	// GITHUB_ENV files, using heredoc syntax for multi-line values.
+	FormatGitHubOutput = "github-output"
+)
+
+// ExportFormats lists the valid formats for Config.Export.
+var ExportFormats = []string{FormatPlain, FormatShell, FormatDotenv, FormatJSON, FormatGitHubOutput}
+
+// Export writes the config's EnvVars to w in format.
+func (c Config) Export(w io.Writer, format string) error {
+	vars, err := c.EnvVars()
+	if err != nil {
+		return err
+	}
+	return ExportVars(w, format, vars)
+}
+
+// ExportVars writes vars to w in format.
+func ExportVars(w io.Writer, format string, vars []EnvVar) error {
+	if format == FormatJSON {
+		obj := make(map[string]string, len(vars))
+		for _, v := range vars {
+			obj[v.Name] = v.Value
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(obj)
+	}
+	var line func(EnvVar) (string, error)
+	switch format {
+	case FormatPlain:
+		line = func(v EnvVar) (string, error) { return v.Name + "=" + v.Value, nil }
+	case FormatShell:
+		line = func(v EnvVar) (string, error) { return "export " + v.Name + "=" + shellQuote(v.Value), nil }
+	case FormatDotenv:
+		line = func(v EnvVar) (string, error) { return v.Name + "=" + dotenvQuote(v.Value), nil }
+	case FormatGitHubOutput:
+		line = githubOutputLine
+	default:
+		return fmt.Errorf("unknown format %q; want one of %s", format, strings.Join(ExportFormats, ", "))
+	}
+	for _, v := range vars {
+		l, err := line(v)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shellQuote single-quotes s, so that nothing in it is expanded. Single
+// quotes can't be escaped inside single quotes, so each is written as a
+// closing quote, an escaped quote, and an opening quote.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// dotenvQuote double-quotes s, escaping newlines so that the value stays on
+// one line, and dollar signs so that they aren't interpolated.
+func dotenvQuote(s string) string {
+	return `"` + strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"$", `\$`,
+	).Replace(s) + `"`
+}
+
+// githubOutputLine writes multi-line values using a heredoc with a random
+// delimiter, as GitHub recommends, so that a value can't end early or inject
+// other variables.
+func githubOutputLine(v EnvVar) (string, error) {
+	if !strings.ContainsAny(v.Value, "\r\n") {
+		return v.Name + "=" + v.Value, nil
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	delim := "ghadelimiter_" + hex.EncodeToString(b)
+	return fmt.Sprintf("%s<<%s\n%s\n%s", v.Name, delim, v.Value, delim), nil
+}
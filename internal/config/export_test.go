@@ -0,0 +1,109 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var exportTestVars = []EnvVar{
+	{"PRODUCT_NAME", "lockbox"},
+	{"INSTRUCTIONS", "cd cmd/lockbox\ngo build -ldflags \"-X 'main.v=$VERSION'\" -o \"$BIN_PATH\"\n"},
+	{"ODD", `back\slash 'single' $(not run) ` + "`nor this`"},
+}
+
+func export(t *testing.T, format string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := ExportVars(&buf, format, exportTestVars); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestExportVars_shell(t *testing.T) {
+	script := export(t, FormatShell) + `printf '%s\0' "$PRODUCT_NAME" "$INSTRUCTIONS" "$ODD"`
+	out, err := exec.Command("sh", "-c", script).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	for i, v := range exportTestVars {
+		if got[i] != v.Value {
+			t.Errorf("%s: got %q; want %q", v.Name, got[i], v.Value)
+		}
+	}
+}
+
+func TestExportVars_dotenv(t *testing.T) {
+	got := export(t, FormatDotenv)
+	want := `PRODUCT_NAME="lockbox"
+INSTRUCTIONS="cd cmd/lockbox\ngo build -ldflags \"-X 'main.v=\$VERSION'\" -o \"\$BIN_PATH\"\n"
+ODD="back\\slash 'single' \$(not run) ` + "`nor this`" + `"
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExportVars_json(t *testing.T) {
+	var got map[string]string
+	if err := json.Unmarshal([]byte(export(t, FormatJSON)), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{}
+	for _, v := range exportTestVars {
+		want[v.Name] = v.Value
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestExportVars_githubOutput(t *testing.T) {
+	got := parseGitHubOutput(t, export(t, FormatGitHubOutput))
+	for _, v := range exportTestVars {
+		if got[v.Name] != v.Value {
+			t.Errorf("%s: got %q; want %q", v.Name, got[v.Name], v.Value)
+		}
+	}
+}
+
+// parseGitHubOutput reads a GITHUB_OUTPUT file the way the runner does.
+func parseGitHubOutput(t *testing.T, s string) map[string]string {
+	out := map[string]string{}
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		if name, value, ok := strings.Cut(lines[i], "="); ok && !strings.Contains(name, "<<") {
+			out[name] = value
+			continue
+		}
+		name, delim, ok := strings.Cut(lines[i], "<<")
+		if !ok {
+			t.Fatalf("line %d: %q is neither NAME=value nor NAME<<DELIMITER", i, lines[i])
+		}
+		var value []string
+		for i++; i < len(lines) && lines[i] != delim; i++ {
+			value = append(value, lines[i])
+		}
+		if i == len(lines) {
+			t.Fatalf("no closing delimiter for %s", name)
+		}
+		out[name] = strings.Join(value, "\n")
+	}
+	return out
+}
+
+func TestExportVars_err(t *testing.T) {
+	err := ExportVars(&bytes.Buffer{}, "yaml", exportTestVars)
+	want := `unknown format "yaml"; want one of plain, shell, dotenv, json, github-output`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v; want %q", err, want)
+	}
+}
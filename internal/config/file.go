@@ -0,0 +1,196 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/composite-action-framework-go/pkg/fs"
+	"github.com/hashicorp/composite-action-framework-go/pkg/git"
+	"github.com/hashicorp/composite-action-framework-go/pkg/json"
+	"github.com/sethvargo/go-envconfig"
+)
+
+// FileName is the checked-in configuration file, relative to the repository
+// root.
+const FileName = ".actions-go-build.json"
+
+// The layers each configuration value can come from, as recorded in
+// Config.Sources. Later layers override earlier ones.
+const (
+	// SourceDerived values are computed from the repository, or are defaults.
+	SourceDerived = "derived"
+	// SourceFile values are read from FileName.
+	SourceFile = FileName
+	// SourceEnv values are read from environment variables.
+	SourceEnv = "env"
+	// SourceProducts values are read from the product's entry in ProductsFile.
+	SourceProducts = ProductsFile
+)
+
+// File is the contents of FileName. All fields are optional, and each is
+// overridden by its environment variable if that's set.
+type File struct {
+	// ProductName is the default for PRODUCT_NAME.
+	ProductName string
+	// VersionStrategy is the default for VERSION_STRATEGY.
+	VersionStrategy string
+	// Instructions is the default for INSTRUCTIONS.
+	Instructions string
+	// Platforms lists the platforms the product is built for, as OS/ARCH.
+	// If OS and ARCH aren't set, the host platform is built if it's listed,
+	// and otherwise the first one. Building any other platform is an error.
+	Platforms []string
+	// Reproducible is the default for REPRODUCIBLE.
+	Reproducible string
+	// ArchiveFormat is the default for ARCHIVE_FORMAT.
+	ArchiveFormat string
+	// EnvAllowlist is the default for ENV_ALLOWLIST.
+	EnvAllowlist []string
+}
+
+// LoadFile reads the FileName in root. If there's no such file, it returns
+// an empty File.
+func LoadFile(root string) (File, error) {
+	path := filepath.Join(root, FileName)
+	exists, err := fs.FileExists(path)
+	if err != nil || !exists {
+		return File{}, err
+	}
+	f, err := json.ReadFile[File](path)
+	if err != nil {
+		return f, fmt.Errorf("reading %s: %w", FileName, err)
+	}
+	for _, p := range f.Platforms {
+		if goos, arch, ok := strings.Cut(p, "/"); !ok || goos == "" || arch == "" {
+			return f, fmt.Errorf("%s: platform %q must be in the form OS/ARCH, e.g. linux/amd64", FileName, p)
+		}
+	}
+	return f, nil
+}
+
+// vars returns the environment variables f sets defaults for.
+func (f File) vars() map[string]string {
+	vars := map[string]string{}
+	for name, value := range map[string]string{
+		"PRODUCT_NAME":     f.ProductName,
+		"VERSION_STRATEGY": f.VersionStrategy,
+		"INSTRUCTIONS":     f.Instructions,
+		"REPRODUCIBLE":     f.Reproducible,
+		"ARCHIVE_FORMAT":   f.ArchiveFormat,
+		"ENV_ALLOWLIST":    strings.Join(f.EnvAllowlist, ","),
+	} {
+		if value != "" {
+			vars[name] = value
+		}
+	}
+	return vars
+}
+
+// selectPlatform sets OS and ARCH from f.Platforms if neither is set, and
+// otherwise checks they're one of f.Platforms.
+func (f File) selectPlatform(c *Config) error {
+	if len(f.Platforms) == 0 {
+		return nil
+	}
+	p := &c.Parameters
+	if p.OS == "" && p.Arch == "" {
+		platform := f.Platforms[0]
+		if host := runtime.GOOS + "/" + runtime.GOARCH; slices.Contains(f.Platforms, host) {
+			platform = host
+		}
+		p.OS, p.Arch, _ = strings.Cut(platform, "/")
+		c.Sources["OS"], c.Sources["ARCH"] = SourceFile, SourceFile
+		return nil
+	}
+	platform := withDefault(p.OS, runtime.GOOS) + "/" + withDefault(p.Arch, runtime.GOARCH)
+	if !slices.Contains(f.Platforms, platform) {
+		return fmt.Errorf("platform %s is not one of the platforms listed in %s: %s",
+			platform, FileName, strings.Join(f.Platforms, ", "))
+	}
+	return nil
+}
+
+func withDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// load reads the configuration in layers: first FileName at the root of the
+// repository containing dir, then the environment.
+func load(dir string) (Config, error) {
+	c := Config{Sources: map[string]string{}}
+	f, err := LoadFile(repoRoot(dir))
+	if err != nil {
+		return c, err
+	}
+	l := &layeredLookuper{
+		sources: c.Sources,
+		layers: []layer{
+			{SourceEnv, envconfig.LookuperFunc(lookupNonEmptyEnv)},
+			{SourceFile, envconfig.MapLookuper(f.vars())},
+		},
+	}
+	if err := envconfig.ProcessWith(context.Background(), &envconfig.Config{Target: &c, Lookuper: l}); err != nil {
+		return c, err
+	}
+	return c, f.selectPlatform(&c)
+}
+
+// repoRoot returns the root of the git repository containing dir, found the
+// same way as the repository context, or dir itself if it's not in one.
+func repoRoot(dir string) string {
+	repo, err := git.Open(dir)
+	if err != nil {
+		return dir
+	}
+	return repo.RootDir()
+}
+
+// lookupNonEmptyEnv treats empty environment variables as unset, since the
+// action sets every input's variable, even if the input's empty.
+func lookupNonEmptyEnv(key string) (string, bool) {
+	v := os.Getenv(key)
+	return v, v != ""
+}
+
+type layer struct {
+	name string
+	envconfig.Lookuper
+}
+
+// layeredLookuper looks each key up in the first layer that has it,
+// recording that layer's name in sources.
+type layeredLookuper struct {
+	// layers are in order of precedence, highest first.
+	layers  []layer
+	sources map[string]string
+}
+
+func (l *layeredLookuper) Lookup(key string) (string, bool) {
+	for _, layer := range l.layers {
+		if v, ok := layer.Lookup(key); ok {
+			l.sources[key] = layer.name
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Source returns the layer the value of the environment variable name came
+// from.
+func (c Config) Source(name string) string {
+	if s, ok := c.Sources[name]; ok {
+		return s
+	}
+	return SourceDerived
+}
@@ -0,0 +1,128 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/composite-action-framework-go/pkg/git"
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+const testConfigFile = `{
+	"ProductName": "lockbox",
+	"Instructions": "go build -o $BIN_PATH",
+	"Platforms": ["plan9/386", "` + runtime.GOOS + `/` + runtime.GOARCH + `"],
+	"Reproducible": "report",
+	"EnvAllowlist": ["GOPROXY", "GOPRIVATE"]
+}`
+
+func writeConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+	root := tmp.Dir(t)
+	if err := os.WriteFile(filepath.Join(root, FileName), []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestLoad_layers(t *testing.T) {
+	root := writeConfigFile(t, testConfigFile)
+	for _, name := range []string{"PRODUCT_NAME", "INSTRUCTIONS", "REPRODUCIBLE", "ENV_ALLOWLIST", "OS", "ARCH", "BIN_NAME"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("REPRODUCIBLE", "nope")
+	t.Setenv("BIN_NAME", "lockboxd")
+
+	c, err := load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{
+		"PRODUCT_NAME":  c.Product.Name,
+		"INSTRUCTIONS":  c.Parameters.Instructions,
+		"REPRODUCIBLE":  c.Reproducible,
+		"ENV_ALLOWLIST": strings.Join(c.Parameters.EnvAllowlist, ","),
+		"OS":            c.Parameters.OS,
+		"ARCH":          c.Parameters.Arch,
+		"BIN_NAME":      c.Product.ExecutableName,
+		"ZIP_NAME":      c.Parameters.ZipName,
+	}
+	want := map[string]string{
+		"PRODUCT_NAME":  "lockbox",
+		"INSTRUCTIONS":  "go build -o $BIN_PATH",
+		"REPRODUCIBLE":  "nope",
+		"ENV_ALLOWLIST": "GOPROXY,GOPRIVATE",
+		"OS":            runtime.GOOS,
+		"ARCH":          runtime.GOARCH,
+		"BIN_NAME":      "lockboxd",
+		"ZIP_NAME":      "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got values %v; want %v", got, want)
+	}
+	wantSources := map[string]string{
+		"PRODUCT_NAME":  SourceFile,
+		"INSTRUCTIONS":  SourceFile,
+		"REPRODUCIBLE":  SourceEnv,
+		"ENV_ALLOWLIST": SourceFile,
+		"OS":            SourceFile,
+		"ARCH":          SourceFile,
+		"BIN_NAME":      SourceEnv,
+		"ZIP_NAME":      SourceDerived,
+	}
+	for name, want := range wantSources {
+		if got := c.Source(name); got != want {
+			t.Errorf("%s: got source %q; want %q", name, got, want)
+		}
+	}
+}
+
+func TestLoad_subdirectory(t *testing.T) {
+	root := writeConfigFile(t, `{"ProductName": "lockbox"}`)
+	if _, err := git.Init(root); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(root, "cmd", "lockbox")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PRODUCT_NAME", "")
+
+	c, err := load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.Product.Name, "lockbox"; got != want {
+		t.Errorf("got product name %q; want %q", got, want)
+	}
+	if got, want := c.Source("PRODUCT_NAME"), SourceFile; got != want {
+		t.Errorf("got source %q; want %q", got, want)
+	}
+}
+
+func TestLoad_platform_err(t *testing.T) {
+	root := writeConfigFile(t, `{"Platforms": ["plan9/386"]}`)
+	t.Setenv("OS", "plan9")
+	t.Setenv("ARCH", "arm")
+	_, err := load(root)
+	want := "platform plan9/arm is not one of the platforms listed in " + FileName + ": plan9/386"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v; want %q", err, want)
+	}
+}
+
+func TestLoadFile_err(t *testing.T) {
+	root := writeConfigFile(t, `{"Platforms": ["linux"]}`)
+	_, err := LoadFile(root)
+	want := FileName + `: platform "linux" must be in the form OS/ARCH, e.g. linux/amd64`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v; want %q", err, want)
+	}
+}
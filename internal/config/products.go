@@ -0,0 +1,91 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/composite-action-framework-go/pkg/fs"
+	"github.com/hashicorp/composite-action-framework-go/pkg/json"
+)
+
+// ProductsFile is where the products in a repository containing more than
+// one product are declared, relative to the repository root.
+const ProductsFile = ".release/products.json"
+
+// Products is the contents of ProductsFile.
+type Products struct {
+	Products []ProductSpec
+}
+
+// ProductSpec declares a single product in a repository containing several.
+// Empty fields fall back to the environment, as for a single product.
+type ProductSpec struct {
+	// Dir is the product directory, relative to the repository root. The
+	// build instructions are run in it.
+	Dir string
+	// Name is the product name. Defaults to the base name of Dir.
+	Name string `json:",omitempty"`
+	// VersionStrategy is how the core version is found, relative to Dir.
+	// See crt.ParseVersionStrategy.
+	VersionStrategy string `json:",omitempty"`
+	// Instructions are the build instructions, run in Dir.
+	Instructions string `json:",omitempty"`
+}
+
+// LoadProducts returns the products declared in root, or nil if root has no
+// ProductsFile.
+func LoadProducts(root string) ([]ProductSpec, error) {
+	path := filepath.Join(root, ProductsFile)
+	exists, err := fs.FileExists(path)
+	if err != nil || !exists {
+		return nil, err
+	}
+	p, err := json.ReadFile[Products](path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ProductsFile, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", ProductsFile, err)
+	}
+	return p.Products, nil
+}
+
+func (p Products) validate() error {
+	if len(p.Products) == 0 {
+		return fmt.Errorf("no products declared")
+	}
+	dirs := map[string]bool{}
+	names := map[string]bool{}
+	for i, spec := range p.Products {
+		dir := filepath.Clean(filepath.FromSlash(spec.Dir))
+		if spec.Dir == "" || filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("product %d: Dir must be a relative path inside the repository, not %q", i, spec.Dir)
+		}
+		if dirs[dir] {
+			return fmt.Errorf("product %d: Dir %q declared more than once", i, spec.Dir)
+		}
+		dirs[dir] = true
+		if dir == "." && spec.Name == "" {
+			return fmt.Errorf("product %d: Name must be set for the product at the repository root", i)
+		}
+		name := spec.DisplayName()
+		if names[name] {
+			return fmt.Errorf("product %d: name %q declared more than once", i, name)
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+// DisplayName returns the product name, or if that's not set, the name of
+// its directory.
+func (s ProductSpec) DisplayName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return filepath.Base(filepath.FromSlash(s.Dir))
+}
@@ -0,0 +1,43 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+func TestLoadProducts_err(t *testing.T) {
+	cases := []struct {
+		desc, json, want string
+	}{
+		{"empty", `{"Products": []}`, "no products declared"},
+		{"no dir", `{"Products": [{"Name": "lockbox"}]}`, `product 0: Dir must be a relative path inside the repository, not ""`},
+		{"outside repo", `{"Products": [{"Dir": "../lockbox"}]}`, `product 0: Dir must be a relative path inside the repository, not "../lockbox"`},
+		{"duplicate dir", `{"Products": [{"Dir": "a"}, {"Dir": "a/", "Name": "b"}]}`, `product 1: Dir "a/" declared more than once`},
+		{"duplicate name", `{"Products": [{"Dir": "a/lockbox"}, {"Dir": "b/lockbox"}]}`, `product 1: name "lockbox" declared more than once`},
+		{"unnamed root", `{"Products": [{"Dir": "."}]}`, `product 0: Name must be set for the product at the repository root`},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			root := tmp.Dir(t)
+			path := filepath.Join(root, ProductsFile)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(c.json), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadProducts(root)
+			if err == nil || !strings.HasSuffix(err.Error(), c.want) {
+				t.Fatalf("got error %v; want error ending %q", err, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/internal/log"
+)
+
+// Defaults used by New.
+const (
+	DefaultConnectTimeout = 30 * time.Second
+	DefaultReadTimeout    = 60 * time.Second
+	DefaultMaxAttempts    = 5
+	DefaultMinBackoff     = time.Second
+	DefaultMaxBackoff     = 30 * time.Second
+	DefaultMaxSize        = 2 << 30
+	// MaxRetryAfter is the longest Retry-After we're willing to wait.
+	// Servers asking for longer than this are treated as failing.
+	MaxRetryAfter = 5 * time.Minute
+)
+
+// Client makes HTTP GET requests, retrying transient failures. Don't use the
+// zero Client, use New.
+type Client struct {
+	http           *http.Client
+	readTimeout    time.Duration
+	maxAttempts    int
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+	maxSize        int64
+	log            log.Func
+	connectTimeout time.Duration
+	// sleep waits for d, or until ctx is done. It's replaced in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithTimeouts sets how long to wait for a connection (including the TLS
+// handshake and response headers), and how long to wait for each read of the
+// response body. Neither limits the total time a download takes.
+func WithTimeouts(connect, read time.Duration) Option {
+	return func(c *Client) { c.connectTimeout, c.readTimeout = connect, read }
+}
+
+// WithRetries sets the maximum number of attempts for each request, and the
+// bounds of the exponential backoff between them.
+func WithRetries(maxAttempts int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) { c.maxAttempts, c.minBackoff, c.maxBackoff = maxAttempts, minBackoff, maxBackoff }
+}
+
+// WithMaxSize sets the maximum number of bytes downloaded from any URL.
+// Zero means no limit.
+func WithMaxSize(n int64) Option { return func(c *Client) { c.maxSize = n } }
+
+// WithLog sets where retries are logged.
+func WithLog(f log.Func) Option { return func(c *Client) { c.log = f } }
+
+// New returns a Client with the default timeouts, retries, and size limit.
+func New(opts ...Option) *Client {
+	c := &Client{
+		connectTimeout: DefaultConnectTimeout,
+		readTimeout:    DefaultReadTimeout,
+		maxAttempts:    DefaultMaxAttempts,
+		minBackoff:     DefaultMinBackoff,
+		maxBackoff:     DefaultMaxBackoff,
+		maxSize:        DefaultMaxSize,
+		log:            func(string, ...any) {},
+		sleep:          sleep,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	if c.maxAttempts < 1 {
+		c.maxAttempts = 1
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: c.connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = c.connectTimeout
+	transport.ResponseHeaderTimeout = c.connectTimeout
+	c.http = &http.Client{Transport: transport}
+	return c
+}
+
+// SizeError is returned when a download exceeds the size limit.
+type SizeError struct {
+	URL   string
+	Limit int64
+}
+
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("GET %s: response exceeds the size limit of %d bytes", e.URL, e.Limit)
+}
+
+// Get GETs url, sending token if it's set, and returns the response body.
+// Failures before the body is returned are retried. The caller must close
+// the body.
+func (c *Client) Get(ctx context.Context, url string, token Token) (io.ReadCloser, error) {
+	var body io.ReadCloser
+	err := c.retry(ctx, url, func(attempt int) (time.Duration, error) {
+		resp, cancel, err := c.do(ctx, url, token, nil)
+		if err != nil {
+			return retryAfter(resp), err
+		}
+		body = &limitedBody{ReadCloser: resp.Body, cancel: cancel, url: url, limit: c.maxSize, remaining: c.maxSize}
+		return 0, nil
+	})
+	return body, err
+}
+
+// Download GETs url into the file at path, sending token if it's set. If the
+// download fails partway through, it's resumed with a Range request where the
+// server supports it, and restarted otherwise.
+func (c *Client) Download(ctx context.Context, url string, token Token, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	var closeErr error
+	defer func() { closeErr = f.Close() }()
+	var written int64
+	// validator is sent as If-Range, so that we only resume if the
+	// resource hasn't changed since the first response.
+	var validator string
+	err = c.retry(ctx, url, func(attempt int) (time.Duration, error) {
+		var rng *byteRange
+		if written > 0 && validator != "" {
+			rng = &byteRange{start: written, validator: validator}
+		}
+		resp, cancel, err := c.do(ctx, url, token, rng)
+		if err != nil {
+			return retryAfter(resp), err
+		}
+		defer cancel()
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusPartialContent {
+			c.log("Resuming download of %s from byte %d", url, written)
+		} else {
+			if written > 0 {
+				c.log("Restarting download of %s", url)
+			}
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return 0, permanent(err)
+			}
+			if err := f.Truncate(0); err != nil {
+				return 0, permanent(err)
+			}
+			written = 0
+			validator = resp.Header.Get("ETag")
+			if validator == "" {
+				validator = resp.Header.Get("Last-Modified")
+			}
+		}
+		if c.maxSize != 0 && written+resp.ContentLength > c.maxSize {
+			return 0, permanent(&SizeError{URL: url, Limit: c.maxSize})
+		}
+		body := &limitedBody{ReadCloser: resp.Body, cancel: cancel, url: url, limit: c.maxSize, remaining: c.maxSize - written}
+		n, err := io.Copy(f, body)
+		written += n
+		var sizeErr *SizeError
+		if errors.As(err, &sizeErr) {
+			return 0, permanent(err)
+		}
+		return 0, err
+	})
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+type byteRange struct {
+	start     int64
+	validator string
+}
+
+// do makes a single request, returning a response with status 200, or 206 if
+// rng is set. On success the caller must call cancel once done with the body.
+// On failure, resp is only non-nil if the server responded.
+func (c *Client) do(ctx context.Context, url string, token Token, rng *byteRange) (*http.Response, context.CancelFunc, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		cancel()
+		return nil, nil, permanent(err)
+	}
+	if token.Value != "" {
+		req.Header.Set("Authorization", "Bearer "+token.Value)
+	}
+	if rng != nil {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", rng.start))
+		req.Header.Set("If-Range", rng.validator)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusOK || (rng != nil && resp.StatusCode == http.StatusPartialContent && rangeStart(resp) == rng.start) {
+		resp.Body = &idleTimeoutBody{ReadCloser: resp.Body, timer: time.AfterFunc(c.readTimeout, cancel), timeout: c.readTimeout}
+		return resp, cancel, nil
+	}
+	resp.Body.Close()
+	cancel()
+	err = &StatusError{URL: url, Status: resp.Status, StatusCode: resp.StatusCode, Token: token}
+	if !retryable(resp.StatusCode) {
+		err = permanent(err)
+	}
+	return resp, nil, err
+}
+
+// rangeStart returns the first byte position in resp's Content-Range header,
+// or -1 if it can't be parsed.
+func rangeStart(resp *http.Response) int64 {
+	var start, end int64
+	var size string
+	if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-%d/%s", &start, &end, &size); err != nil {
+		return -1
+	}
+	return start
+}
+
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryAfter returns the delay requested by resp's Retry-After header, or
+// zero if there isn't one.
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+type permanentError struct{ error }
+
+func (e permanentError) Unwrap() error { return e.error }
+
+// permanent marks err as not worth retrying.
+func permanent(err error) error { return permanentError{err} }
+
+// retry calls attempt until it succeeds, returns a permanent error, or the
+// attempts run out. Attempt returns the delay the server asked for, if any.
+func (c *Client) retry(ctx context.Context, url string, attempt func(int) (time.Duration, error)) error {
+	backoff := c.minBackoff
+	for i := 1; ; i++ {
+		wait, err := attempt(i)
+		if err == nil {
+			return nil
+		}
+		var p permanentError
+		if errors.As(err, &p) {
+			return p.error
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if i >= c.maxAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", i, err)
+		}
+		if wait > MaxRetryAfter {
+			return fmt.Errorf("server asked to retry after %s, longer than %s: %w", wait, MaxRetryAfter, err)
+		}
+		if wait == 0 {
+			// Full jitter, so that many clients don't retry in lockstep.
+			wait = backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+			if backoff *= 2; backoff > c.maxBackoff {
+				backoff = c.maxBackoff
+			}
+		}
+		c.log("Retrying %s in %s (attempt %d of %d failed: %s)", url, wait.Round(time.Millisecond), i, c.maxAttempts, err)
+		if err := c.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// idleTimeoutBody cancels the request if any single read takes longer than
+// timeout, so a stalled connection fails rather than hanging forever.
+type idleTimeoutBody struct {
+	io.ReadCloser
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.timer.Reset(b.timeout)
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	return b.ReadCloser.Close()
+}
+
+// limitedBody returns a SizeError once more than remaining bytes are read,
+// unless limit is zero. Closing it releases the request context.
+type limitedBody struct {
+	io.ReadCloser
+	cancel           context.CancelFunc
+	url              string
+	limit, remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.limit == 0 {
+		return n, err
+	}
+	if b.remaining -= int64(n); b.remaining < 0 {
+		return n, &SizeError{URL: b.url, Limit: b.limit}
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
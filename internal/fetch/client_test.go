@@ -0,0 +1,180 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testClient returns a client which records the delays it would sleep for,
+// rather than sleeping.
+func testClient(opts ...Option) (*Client, *[]time.Duration) {
+	c := New(opts...)
+	var slept []time.Duration
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	return c, &slept
+}
+
+func TestClient_Get_retries(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = io.WriteString(w, "config")
+		}
+	}))
+	defer srv.Close()
+
+	c, slept := testClient(WithRetries(3, 2*time.Second, time.Minute))
+	body, err := c.Get(context.Background(), srv.URL, Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	got, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "config" {
+		t.Errorf("got body %q; want %q", got, "config")
+	}
+	if len(*slept) != 2 {
+		t.Fatalf("got %d retries; want 2", len(*slept))
+	}
+	if (*slept)[0] != 7*time.Second {
+		t.Errorf("got first delay %s; want Retry-After of 7s", (*slept)[0])
+	}
+	if d := (*slept)[1]; d < time.Second || d > 2*time.Second {
+		t.Errorf("got second delay %s; want between 1s and 2s", d)
+	}
+}
+
+func TestClient_Get_err(t *testing.T) {
+	cases := []struct {
+		desc         string
+		status       int
+		wantRequests int
+		wantErr      string
+	}{
+		{"not found is not retried", http.StatusNotFound, 1, ": 404 Not Found"},
+		{"server error is retried", http.StatusInternalServerError, 3, ": 500 Internal Server Error"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			var requests int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(c.status)
+			}))
+			defer srv.Close()
+			client, _ := testClient(WithRetries(3, 0, 0))
+			_, err := client.Get(context.Background(), srv.URL, Token{})
+			if err == nil || !strings.HasSuffix(err.Error(), c.wantErr) {
+				t.Errorf("got error %v; want error ending %q", err, c.wantErr)
+			}
+			if requests != c.wantRequests {
+				t.Errorf("got %d requests; want %d", requests, c.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClient_Download_resume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		if len(ranges) == 1 {
+			// Send half the content, then drop the connection.
+			w.Header().Set("Content-Length", "100000")
+			_, _ = w.Write(content[:50000])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "source.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	client, _ := testClient()
+	path := filepath.Join(t.TempDir(), "source.zip")
+	if err := client.Download(context.Background(), srv.URL, Token{}, path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("downloaded %d bytes that don't match the %d bytes served", len(got), len(content))
+	}
+	if len(ranges) != 2 || ranges[0] != "" || ranges[1] != "bytes=50000-" {
+		t.Errorf("got Range headers %q; want [\"\" \"bytes=50000-\"]", ranges)
+	}
+}
+
+func TestClient_Download_err_tooBig(t *testing.T) {
+	for _, chunked := range []bool{false, true} {
+		chunked := chunked
+		t.Run(map[bool]string{false: "content length", true: "chunked"}[chunked], func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if chunked {
+					w.Header().Set("Transfer-Encoding", "chunked")
+					w.(http.Flusher).Flush()
+				}
+				_, _ = w.Write(make([]byte, 2000))
+			}))
+			defer srv.Close()
+			client, slept := testClient(WithMaxSize(1000))
+			err := client.Download(context.Background(), srv.URL, Token{}, filepath.Join(t.TempDir(), "big"))
+			var sizeErr *SizeError
+			if !errors.As(err, &sizeErr) {
+				t.Fatalf("got error %v; want a SizeError", err)
+			}
+			if len(*slept) != 0 {
+				t.Errorf("got %d retries; want none", len(*slept))
+			}
+		})
+	}
+}
+
+func TestClient_Download_err_readTimeout(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "10")
+		_, _ = w.Write([]byte("01234"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	client, _ := testClient(WithTimeouts(time.Second, 50*time.Millisecond), WithRetries(2, 0, 0))
+	err := client.Download(context.Background(), srv.URL, Token{}, filepath.Join(t.TempDir(), "slow"))
+	if err == nil || !strings.Contains(err.Error(), "giving up after 2 attempts") {
+		t.Fatalf("got error %v; want error after 2 attempts", err)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+// Package fetch downloads things over HTTP.
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Token is a bearer token read from an environment variable.
+// Only Env is ever printed. The zero Token means no token applies.
+type Token struct {
+	// Env is the name of the environment variable the token came from.
+	Env string
+	// Value is the token itself. If empty, requests are unauthenticated.
+	Value string
+}
+
+// String never reveals the token's value, so it's safe to log a Token.
+func (t Token) String() string {
+	if t.Value == "" {
+		return fmt.Sprintf("no token ($%s not set)", t.Env)
+	}
+	return fmt.Sprintf("token from $%s", t.Env)
+}
+
+// StatusError is returned when a server responds with anything but 200 OK.
+type StatusError struct {
+	URL        string
+	Status     string
+	StatusCode int
+	Token      Token
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("GET %s: %s", e.URL, e.Status)
+	switch e.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+	default:
+		return msg
+	}
+	if e.Token.Env == "" {
+		// No token is applicable to this host.
+		return msg
+	}
+	if e.Token.Value == "" {
+		return fmt.Sprintf("%s; if this is a private repository, set $%s to a token which can read it", msg, e.Token.Env)
+	}
+	return fmt.Sprintf("%s; check that the token in $%s is valid and can read this repository", msg, e.Token.Env)
+}
+
+// Get GETs url using a Client with the default settings. See Client.Get.
+func Get(url string, token Token) (io.ReadCloser, error) {
+	return New().Get(context.Background(), url, token)
+}
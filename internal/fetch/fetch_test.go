@@ -0,0 +1,80 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package fetch
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const secret = "ghp_s3cr3t"
+
+func TestGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+secret {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, "source")
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		desc    string
+		token   Token
+		want    string
+		wantErr string
+	}{
+		{"authenticated", Token{Env: "GITHUB_TOKEN", Value: secret}, "source", ""},
+		{
+			"no token",
+			Token{Env: "GITHUB_TOKEN"},
+			"",
+			"404 Not Found; if this is a private repository, set $GITHUB_TOKEN to a token which can read it",
+		},
+		{
+			"bad token",
+			Token{Env: "MY_TOKEN", Value: "nope"},
+			"",
+			"404 Not Found; check that the token in $MY_TOKEN is valid and can read this repository",
+		},
+		{"token not applicable", Token{}, "", srv.URL + ": 404 Not Found"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			body, err := Get(srv.URL, c.token)
+			if c.wantErr != "" {
+				if err == nil || !strings.HasSuffix(err.Error(), c.wantErr) {
+					t.Fatalf("got error %v; want error ending %q", err, c.wantErr)
+				}
+				if strings.Contains(err.Error(), secret) || strings.Contains(err.Error(), "nope") {
+					t.Fatalf("error %q reveals the token", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer body.Close()
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != c.want {
+				t.Fatalf("got body %q; want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestToken_String(t *testing.T) {
+	got := Token{Env: "GITHUB_TOKEN", Value: secret}.String()
+	if want := "token from $GITHUB_TOKEN"; got != want {
+		t.Fatalf("got %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package filetree
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Type is the type of an Entry.
+type Type int
+
+const (
+	File Type = iota
+	Dir
+	Symlink
+)
+
+// Entry is a single entry to be written to an archive. Walk produces entries
+// so that zips, tarballs, and image layers all agree on names and ordering.
+type Entry struct {
+	// Name is the slash-separated name of the entry within the archive.
+	// Directory names have a trailing slash.
+	Name string
+	// Path is the path of the entry on disk.
+	Path string
+	// Type is the type of entry.
+	Type Type
+	// LinkTarget is the relative target of a Symlink entry.
+	LinkTarget string
+}
+
+// Walk calls fn for each entry in dir, in lexical order. The root directory
+// itself is never passed to fn.
+//
+// If preservePaths is false, the hierarchy is flattened: only files are
+// passed to fn, each named by its base name, and symlinks are followed.
+//
+// If preservePaths is true, entries are named by their path relative to dir,
+// directories are passed to fn as well as files, and symlinks are passed as
+// Symlink entries as long as they point to somewhere inside dir using a
+// relative path. Any other symlink results in an error, because it would
+// make the archive's contents depend on the host it was extracted on.
+func Walk(dir string, preservePaths bool, fn func(Entry) error) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if !preservePaths {
+			if d.IsDir() {
+				return nil
+			}
+			return fn(Entry{Name: filepath.Base(p), Path: p, Type: File})
+		}
+		name := filepath.ToSlash(rel)
+		switch {
+		case d.IsDir():
+			return fn(Entry{Name: name + "/", Path: p, Type: Dir})
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := safeLinkTarget(dir, p, name)
+			if err != nil {
+				return err
+			}
+			return fn(Entry{Name: name, Path: p, Type: Symlink, LinkTarget: target})
+		case d.Type().IsRegular():
+			return fn(Entry{Name: name, Path: p, Type: File})
+		}
+		return fmt.Errorf("%q is not a regular file, directory, or symlink", p)
+	})
+}
+
+func safeLinkTarget(root, p, name string) (string, error) {
+	target, err := os.Readlink(p)
+	if err != nil {
+		return "", err
+	}
+	target = filepath.ToSlash(target)
+	if path.IsAbs(target) || filepath.IsAbs(target) {
+		return "", fmt.Errorf("symlink %q has absolute target %q", name, target)
+	}
+	if err := CheckInside(root, name); err != nil {
+		return "", fmt.Errorf("symlink %q points outside the archive root: %w", name, err)
+	}
+	return target, nil
+}
+
+// maxLinkHops limits how many symlinks CheckInside follows, so that loops
+// are an error rather than hanging.
+const maxLinkHops = 255
+
+// CheckInside returns an error if name, a slash-separated path relative to
+// root, would resolve to somewhere outside root once every symlink along it,
+// including name itself, is followed. Checking the path text alone isn't
+// enough, since a target like "b/c/.." escapes if b/c is itself a link to
+// "..". Components which don't exist are treated as directories.
+func CheckInside(root, name string) error {
+	var resolved []string
+	pending := strings.Split(name, "/")
+	for hops := 0; len(pending) != 0; {
+		c := pending[0]
+		pending = pending[1:]
+		switch c {
+		case "", ".":
+			continue
+		case "..":
+			if len(resolved) == 0 {
+				return fmt.Errorf("%q resolves outside %s", name, root)
+			}
+			resolved = resolved[:len(resolved)-1]
+			continue
+		}
+		resolved = append(resolved, c)
+		p := filepath.Join(root, filepath.FromSlash(path.Join(resolved...)))
+		info, err := os.Lstat(p)
+		if err != nil || info.Mode()&fs.ModeSymlink == 0 {
+			continue
+		}
+		if hops++; hops > maxLinkHops {
+			return fmt.Errorf("%q: too many levels of symlinks", name)
+		}
+		target, err := os.Readlink(p)
+		if err != nil {
+			return err
+		}
+		target = filepath.ToSlash(target)
+		if path.IsAbs(target) || filepath.IsAbs(target) {
+			return fmt.Errorf("%q resolves through symlink %q with absolute target %q", name, path.Join(resolved...), target)
+		}
+		// Replace the link with its target, relative to the link's directory.
+		resolved = resolved[:len(resolved)-1]
+		pending = append(strings.Split(target, "/"), pending...)
+	}
+	return nil
+}
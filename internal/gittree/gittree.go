@@ -0,0 +1,135 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+// Package gittree computes git object hashes of directories on disk, without
+// needing a git repository.
+package gittree
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	modeFile    = "100644"
+	modeExec    = "100755"
+	modeSymlink = "120000"
+	modeTree    = "40000"
+)
+
+// Hash returns the SHA-1 git tree hash of dir. For a directory containing
+// exactly the files committed at some revision, for example an extracted
+// source archive, it's the same as the tree hash of that revision's commit.
+//
+// As in git, empty directories are ignored, only the executable bit of each
+// file's mode is significant, and any .git directory is skipped.
+func Hash(dir string) (string, error) {
+	sum, _, err := hashTree(dir)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum), nil
+}
+
+type entry struct {
+	mode, name string
+	sum        []byte
+}
+
+// sortKey is the name git uses to order tree entries: trees sort as if
+// their name had a trailing slash.
+func (e entry) sortKey() string {
+	if e.mode == modeTree {
+		return e.name + "/"
+	}
+	return e.name
+}
+
+// hashTree returns the tree hash of dir, and false if dir contains no files.
+func hashTree(dir string) ([]byte, bool, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, false, err
+	}
+	var entries []entry
+	for _, d := range dirEntries {
+		e, ok, err := hashEntry(filepath.Join(dir, d.Name()), d)
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, false, nil
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].sortKey() < entries[j].sortKey() })
+	var buf bytes.Buffer
+	for _, e := range entries {
+		fmt.Fprintf(&buf, "%s %s\x00", e.mode, e.name)
+		buf.Write(e.sum)
+	}
+	return objectHash("tree", int64(buf.Len()), &buf)
+}
+
+func hashEntry(path string, d fs.DirEntry) (entry, bool, error) {
+	e := entry{name: d.Name()}
+	var err error
+	switch {
+	case d.IsDir():
+		if e.name == ".git" {
+			return e, false, nil
+		}
+		e.mode = modeTree
+		var nonEmpty bool
+		e.sum, nonEmpty, err = hashTree(path)
+		return e, nonEmpty, err
+	case d.Type()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return e, false, err
+		}
+		target = filepath.ToSlash(target)
+		e.mode = modeSymlink
+		e.sum, _, err = objectHash("blob", int64(len(target)), bytes.NewBufferString(target))
+		return e, true, err
+	case d.Type().IsRegular():
+		info, err := d.Info()
+		if err != nil {
+			return e, false, err
+		}
+		e.mode = modeFile
+		if info.Mode()&0o111 != 0 {
+			e.mode = modeExec
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return e, false, err
+		}
+		defer f.Close()
+		e.sum, _, err = objectHash("blob", info.Size(), f)
+		return e, true, err
+	}
+	return e, false, fmt.Errorf("%q is not a regular file, directory, or symlink", path)
+}
+
+func objectHash(kind string, size int64, r io.Reader) ([]byte, bool, error) {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s %d\x00", kind, size)
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return nil, false, err
+	}
+	if n != size {
+		return nil, false, fmt.Errorf("%s changed size while hashing", kind)
+	}
+	return h.Sum(nil), true, nil
+}
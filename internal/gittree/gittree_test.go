@@ -0,0 +1,62 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package gittree
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/hashicorp/composite-action-framework-go/pkg/git"
+)
+
+func TestHash_matchesGit(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.go"), "package main\n", 0o644)
+	writeFile(t, filepath.Join(dir, "build.sh"), "#!/bin/sh\n", 0o755)
+	writeFile(t, filepath.Join(dir, "a", "b", "c.txt"), "c\n", 0o600)
+	// "a.txt" sorts before the tree "a" in git, because trees sort as "a/".
+	writeFile(t, filepath.Join(dir, "a.txt"), "a\n", 0o644)
+	must(t, os.Symlink("a/b/c.txt", filepath.Join(dir, "link")))
+
+	repo, err := git.Init(dir, git.WithAuthor("test", "test@test.com"))
+	must(t, err)
+	must(t, repo.Add("."))
+	must(t, repo.Commit("initial commit"))
+
+	// Empty directories aren't part of git trees.
+	must(t, os.Mkdir(filepath.Join(dir, "empty"), 0o755))
+
+	got, err := Hash(dir)
+	must(t, err)
+	if want := headTreeHash(t, dir); got != want {
+		t.Fatalf("got tree hash %s; want %s", got, want)
+	}
+}
+
+func headTreeHash(t *testing.T, dir string) string {
+	t.Helper()
+	r, err := gogit.PlainOpen(dir)
+	must(t, err)
+	head, err := r.Head()
+	must(t, err)
+	c, err := r.CommitObject(head.Hash())
+	must(t, err)
+	return c.TreeHash.String()
+}
+
+func writeFile(t *testing.T, path, contents string, mode os.FileMode) {
+	t.Helper()
+	must(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	must(t, os.WriteFile(path, []byte(contents), mode))
+	must(t, os.Chmod(path, mode))
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
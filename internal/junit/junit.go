@@ -0,0 +1,215 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+// Package junit converts build and verification results to JUnit XML, so
+// that they can be ingested by CI dashboards.
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	"github.com/hashicorp/actions-go-build/pkg/crt"
+	"github.com/hashicorp/composite-action-framework-go/pkg/fs"
+)
+
+// TestSuites is the root element of a JUnit XML report.
+type TestSuites struct {
+	XMLName  xml.Name    `xml:"testsuites"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Suites   []TestSuite `xml:"testsuite"`
+}
+
+// TestSuite describes a single build or verification.
+type TestSuite struct {
+	Name       string     `xml:"name,attr"`
+	Tests      int        `xml:"tests,attr"`
+	Failures   int        `xml:"failures,attr"`
+	Time       string     `xml:"time,attr"`
+	Properties []Property `xml:"properties>property,omitempty"`
+	Cases      []TestCase `xml:"testcase"`
+}
+
+// Property is a name and value describing a test suite.
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// TestCase is a single build step or artifact comparison.
+type TestCase struct {
+	Name      string   `xml:"name,attr"`
+	Classname string   `xml:"classname,attr"`
+	Time      string   `xml:"time,attr"`
+	Failure   *Failure `xml:"failure,omitempty"`
+}
+
+// Failure describes why a test case failed.
+type Failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Classnames of test cases.
+const (
+	ClassBuild        = "build"
+	ClassPrimary      = "primary"
+	ClassVerification = "verification"
+	ClassComparison   = "comparison"
+)
+
+// ForResult returns a test suite with a test case for each step of the build.
+func ForResult(r build.Result) TestSuite {
+	s := newSuite(r)
+	s.addSteps(ClassBuild, r)
+	return s.finish(r.Meta.Duration)
+}
+
+// ForVerification returns a test suite with a test case for each step of
+// the primary and verification builds, and for each artifact comparison.
+// Mismatched artifacts are failures containing both hashes.
+func ForVerification(vr build.VerificationResult) TestSuite {
+	if vr.Primary == nil {
+		s := TestSuite{Name: "verification"}
+		s.add(TestCase{Name: "verification", Classname: ClassVerification, Failure: &Failure{
+			Message: "verification result has no primary build result",
+			Type:    "error",
+		}})
+		return s.finish("")
+	}
+	s := newSuite(*vr.Primary)
+	s.addSteps(ClassPrimary, *vr.Primary)
+	total := parseDuration(vr.Primary.Meta.Duration)
+	if vr.Verification != nil {
+		s.addSteps(ClassVerification, *vr.Verification)
+		total += parseDuration(vr.Verification.Meta.Duration)
+	}
+	h := vr.Hashes
+	failed := s.Failures
+	for _, fh := range append([]crt.FileHashes{h.Bin, h.Zip}, h.Extra...) {
+		s.add(comparisonCase(fh))
+	}
+	// Differences in names or sizes fail verification without the hashes
+	// mismatching, so make sure they're reported somewhere.
+	if !vr.ReproducedCorrectly && s.Failures == failed {
+		s.add(TestCase{Name: "reproduced correctly", Classname: ClassComparison, Failure: &Failure{
+			Message: vr.ErrorMessage,
+			Type:    "mismatch",
+		}})
+	}
+	return s.finish(total.String())
+}
+
+func newSuite(r build.Result) TestSuite {
+	c := r.Config
+	platform := c.Parameters.OS + "/" + c.Parameters.Arch
+	return TestSuite{
+		Name: fmt.Sprintf("%s %s %s", c.Product.Name, c.Product.Version.Full, platform),
+		Properties: []Property{
+			{"product", c.Product.Name},
+			{"version", c.Product.Version.Full},
+			{"revision", c.Product.Revision},
+			{"platform", platform},
+			{"config-id", c.ID()},
+			{"cached", strconv.FormatBool(r.FromCache)},
+		},
+	}
+}
+
+func (s *TestSuite) add(c TestCase) {
+	s.Cases = append(s.Cases, c)
+	s.Tests++
+	if c.Failure != nil {
+		s.Failures++
+	}
+}
+
+func (s *TestSuite) addSteps(class string, r build.Result) {
+	for _, step := range r.Steps {
+		c := TestCase{Name: step.Description, Classname: class, Time: seconds(step.Duration)}
+		if step.ErrorMessage != "" {
+			c.Failure = &Failure{Message: step.ErrorMessage, Type: "error"}
+		}
+		s.add(c)
+	}
+	// Results written before steps were recorded, or which failed
+	// before running any steps, still need to report their error.
+	if len(r.Steps) == 0 && !r.Successful {
+		s.add(TestCase{Name: "build", Classname: class, Failure: &Failure{Message: r.ErrorMessage, Type: "error"}})
+	}
+}
+
+func (s TestSuite) finish(duration string) TestSuite {
+	s.Time = seconds(duration)
+	return s
+}
+
+func comparisonCase(fh crt.FileHashes) TestCase {
+	c := TestCase{Name: fh.Description, Classname: ClassComparison, Time: seconds("")}
+	if fh.Name != "" {
+		c.Name += " " + fh.Name
+	}
+	if !fh.SHA256.Match {
+		c.Failure = &Failure{
+			Message: fmt.Sprintf("%s mismatch", fh.Description),
+			Type:    "mismatch",
+			Text:    fmt.Sprintf("primary SHA256:      %s\nverification SHA256: %s\n", fh.SHA256.Primary, fh.SHA256.Verification),
+		}
+	}
+	return c
+}
+
+// Write writes suites to w as a JUnit XML report.
+func Write(w io.Writer, name string, suites ...TestSuite) error {
+	report := TestSuites{Name: name, Suites: suites}
+	var total float64
+	for _, s := range suites {
+		report.Tests += s.Tests
+		report.Failures += s.Failures
+		t, _ := strconv.ParseFloat(s.Time, 64)
+		total += t
+	}
+	report.Time = strconv.FormatFloat(total, 'f', 3, 64)
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteFile writes suites to a JUnit XML file at path.
+func WriteFile(path, name string, suites ...TestSuite) error {
+	f, err := fs.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := Write(f, name, suites...); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// seconds converts a duration string, as recorded in build results, to
+// seconds, as used by JUnit.
+func seconds(d string) string {
+	return strconv.FormatFloat(parseDuration(d).Seconds(), 'f', 3, 64)
+}
+
+func parseDuration(d string) time.Duration {
+	dur, _ := time.ParseDuration(d)
+	return dur
+}
@@ -0,0 +1,96 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package junit
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/actions-go-build/internal/testhelpers/buildtest"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	"github.com/hashicorp/actions-go-build/pkg/crt"
+)
+
+// timedResult returns a result taking 1.5s, which ran steps.
+func timedResult(steps ...build.StepResult) *build.Result {
+	r := buildtest.Result()
+	r.Steps = steps
+	r.Meta.Duration = "1.5s"
+	return &r
+}
+
+func TestForResult(t *testing.T) {
+	r := timedResult(
+		build.StepResult{Description: "validating inputs", Duration: "10ms"},
+		build.StepResult{Description: "running build instructions", Duration: "1s", ErrorMessage: "exit status 1"},
+	)
+	r.Successful = false
+	s := ForResult(*r)
+	if s.Name != "lockbox 1.2.3 linux/amd64" {
+		t.Errorf("got name %q; want %q", s.Name, "lockbox 1.2.3 linux/amd64")
+	}
+	want := []TestCase{
+		{Name: "validating inputs", Classname: ClassBuild, Time: "0.010"},
+		{Name: "running build instructions", Classname: ClassBuild, Time: "1.000", Failure: &Failure{Message: "exit status 1", Type: "error"}},
+	}
+	if !reflect.DeepEqual(s.Cases, want) {
+		t.Errorf("got cases %+v; want %+v", s.Cases, want)
+	}
+	if s.Tests != 2 || s.Failures != 1 || s.Time != "1.500" {
+		t.Errorf("got tests=%d failures=%d time=%s; want tests=2 failures=1 time=1.500", s.Tests, s.Failures, s.Time)
+	}
+}
+
+func TestForVerification(t *testing.T) {
+	step := build.StepResult{Description: "running build instructions", Duration: "1s"}
+	vr := build.VerificationResult{
+		Primary:      timedResult(step),
+		Verification: timedResult(step),
+		Hashes: crt.NewFileSetHashes(
+			crt.FileHashes{Name: "lockbox", Description: "executable", SHA256: crt.HashPair{Primary: "aaaa", Verification: "aaaa", Match: true}},
+			crt.FileHashes{Name: "lockbox.zip", Description: "zip", SHA256: crt.HashPair{Primary: "bbbb", Verification: "cccc"}},
+		),
+		ErrorMessage: "digests are different",
+	}
+	s := ForVerification(vr)
+	var got []string
+	for _, c := range s.Cases {
+		got = append(got, c.Classname+": "+c.Name)
+	}
+	want := []string{
+		"primary: running build instructions",
+		"verification: running build instructions",
+		"comparison: executable lockbox",
+		"comparison: zip lockbox.zip",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got cases %q; want %q", got, want)
+	}
+	f := s.Cases[3].Failure
+	if f == nil || f.Message != "zip mismatch" || !bytes.Contains([]byte(f.Text), []byte("verification SHA256: cccc")) {
+		t.Errorf("got failure %+v; want a zip mismatch with both hashes", f)
+	}
+	if s.Failures != 1 || s.Time != "3.000" {
+		t.Errorf("got failures=%d time=%s; want failures=1 time=3.000", s.Failures, s.Time)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "verify", ForResult(*timedResult()), ForResult(*timedResult())); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte(xml.Header+"<testsuites name=\"verify\"")) {
+		t.Errorf("got:\n%s\nwant an XML header followed by testsuites", buf.String())
+	}
+	var got TestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Suites) != 2 || got.Time != "3.000" {
+		t.Errorf("got %d suites taking %s; want 2 taking 3.000", len(got.Suites), got.Time)
+	}
+}
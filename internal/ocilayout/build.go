@@ -0,0 +1,323 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package ocilayout
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/internal/tarball"
+	cafs "github.com/hashicorp/composite-action-framework-go/pkg/fs"
+)
+
+const mediaTypeDockerLayerGzip = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+
+// Options configure the image assembled by Build.
+type Options struct {
+	// BaseLayout is the path to a local OCI image layout directory containing
+	// the base image.
+	BaseLayout string
+	// OS and Arch select the base image manifest and are written to the image config.
+	OS, Arch string
+	// Created is used for the image creation time and every timestamp in the new layer.
+	Created time.Time
+	// Name and Tag are used to tag the image in the output layout's index.
+	Name, Tag string
+	// PreservePaths keeps the directory structure of the layer's files,
+	// see tarball.WithPreservePaths.
+	PreservePaths bool
+	// Entrypoint, if set, is the absolute path in the image of the executable to run.
+	Entrypoint string
+	// Log is the log func.
+	Log func(string, ...any)
+}
+
+// Build assembles an OCI image layout at dest comprising the base image plus
+// a single new layer containing the files in dir at the image root. If dest
+// ends with ".tar" the layout is written as a tarball, otherwise as a directory.
+// All output is deterministic given the same base image, files, and options.
+// It returns the descriptor of the new image manifest.
+func Build(dir, dest string, opts Options) (Descriptor, error) {
+	base, err := openLayout(opts.BaseLayout)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	_, baseManifest, err := base.resolveManifest(opts.OS, opts.Arch)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	var config map[string]any
+	if err := base.readBlobJSON(baseManifest.Config, &config); err != nil {
+		return Descriptor{}, fmt.Errorf("reading base image config: %w", err)
+	}
+
+	layerFile, err := os.CreateTemp("", "actions-go-build.layer.*.tar.gz")
+	if err != nil {
+		return Descriptor{}, err
+	}
+	defer os.Remove(layerFile.Name())
+	defer layerFile.Close()
+
+	opts.Log("Creating image layer from %q", dir)
+	layer, diffID, err := writeLayer(layerFile, dir, opts)
+	if err != nil {
+		return Descriptor{}, fmt.Errorf("creating layer: %w", err)
+	}
+
+	configBytes, err := updateConfig(config, diffID, opts)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	configDesc := descriptorFor(MediaTypeImageConfig, configBytes)
+
+	blobs := []blob{fileBlob(layer, layerFile.Name()), bytesBlob(configDesc, configBytes)}
+	layers := make([]Descriptor, 0, len(baseManifest.Layers)+1)
+	for _, l := range baseManifest.Layers {
+		p, err := base.blobPath(l.Digest)
+		if err != nil {
+			return Descriptor{}, err
+		}
+		if l.MediaType == mediaTypeDockerLayerGzip {
+			l.MediaType = MediaTypeLayerGzip
+		}
+		layers = append(layers, l)
+		blobs = append(blobs, fileBlob(l, p))
+	}
+	layers = append(layers, layer)
+
+	manifestBytes, err := json.Marshal(Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		Config:        configDesc,
+		Layers:        layers,
+	})
+	if err != nil {
+		return Descriptor{}, err
+	}
+	manifestDesc := descriptorFor(MediaTypeImageManifest, manifestBytes)
+	blobs = append(blobs, bytesBlob(manifestDesc, manifestBytes))
+
+	manifestDesc.Annotations = map[string]string{
+		AnnotationRefName:   opts.Tag,
+		AnnotationImageName: fmt.Sprintf("%s:%s", opts.Name, opts.Tag),
+	}
+	manifestDesc.Platform = &Platform{OS: opts.OS, Architecture: opts.Arch}
+	indexBytes, err := json.Marshal(Index{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageIndex,
+		Manifests:     []Descriptor{manifestDesc},
+	})
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	opts.Log("Writing image %s:%s (%s) to %q", opts.Name, opts.Tag, manifestDesc.Digest, dest)
+	if err := writeLayout(dest, opts.Created, blobs, indexBytes); err != nil {
+		return Descriptor{}, err
+	}
+	return manifestDesc, nil
+}
+
+// writeLayer writes a gzipped tarball of dir to w and returns its descriptor
+// along with its diff ID (the digest of the uncompressed tarball).
+func writeLayer(w io.Writer, dir string, opts Options) (Descriptor, string, error) {
+	compressed := sha256.New()
+	counter := &countWriter{}
+	gz, err := tarball.Compress(io.MultiWriter(w, compressed, counter), tarball.Gzip)
+	if err != nil {
+		return Descriptor{}, "", err
+	}
+	uncompressed := sha256.New()
+	tw := tarball.New(io.MultiWriter(gz, uncompressed), opts.Created, opts.Log, tarball.WithPreservePaths(opts.PreservePaths))
+	if err := tw.AddDir(dir, ""); err != nil {
+		return Descriptor{}, "", err
+	}
+	if err := tw.Close(); err != nil {
+		return Descriptor{}, "", err
+	}
+	if err := gz.Close(); err != nil {
+		return Descriptor{}, "", err
+	}
+	d := Descriptor{
+		MediaType: MediaTypeLayerGzip,
+		Digest:    formatDigest(compressed.Sum(nil)),
+		Size:      counter.n,
+	}
+	return d, formatDigest(uncompressed.Sum(nil)), nil
+}
+
+// updateConfig adds the new layer to the base image config, and stamps it with
+// the platform, creation time, and entrypoint. Unknown fields are preserved.
+// The result is marshalled with sorted keys, so it is deterministic.
+func updateConfig(config map[string]any, diffID string, opts Options) ([]byte, error) {
+	created := opts.Created.UTC().Format(time.RFC3339)
+	config["created"] = created
+	config["os"] = opts.OS
+	config["architecture"] = opts.Arch
+
+	rootfs, _ := config["rootfs"].(map[string]any)
+	if rootfs == nil {
+		rootfs = map[string]any{"type": "layers"}
+	}
+	diffIDs, _ := rootfs["diff_ids"].([]any)
+	rootfs["diff_ids"] = append(diffIDs, diffID)
+	config["rootfs"] = rootfs
+
+	history, _ := config["history"].([]any)
+	config["history"] = append(history, map[string]any{
+		"created":    created,
+		"created_by": "actions-go-build",
+	})
+
+	if opts.Entrypoint != "" {
+		runtime, _ := config["config"].(map[string]any)
+		if runtime == nil {
+			runtime = map[string]any{}
+		}
+		runtime["Entrypoint"] = []string{opts.Entrypoint}
+		config["config"] = runtime
+	}
+	return json.Marshal(config)
+}
+
+type blob struct {
+	Descriptor
+	open func() (io.ReadCloser, error)
+}
+
+func fileBlob(d Descriptor, path string) blob {
+	return blob{d, func() (io.ReadCloser, error) { return os.Open(path) }}
+}
+
+func bytesBlob(d Descriptor, b []byte) blob {
+	return blob{d, func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(b)), nil }}
+}
+
+// name returns the slash-separated path of the blob within a layout.
+func (b blob) name() string {
+	return blobsDir + "/" + strings.Replace(b.Digest, ":", "/", 1)
+}
+
+func writeLayout(dest string, created time.Time, blobs []blob, index []byte) error {
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].Digest < blobs[j].Digest })
+	// A base image may legitimately reference the same layer more than once.
+	unique := blobs[:0]
+	for _, b := range blobs {
+		if len(unique) == 0 || b.Digest != unique[len(unique)-1].Digest {
+			unique = append(unique, b)
+		}
+	}
+	blobs = unique
+	marker, err := json.Marshal(layoutMarker{ImageLayoutVersion: layoutVersion})
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(dest, ".tar") {
+		return writeLayoutTarball(dest, created, blobs, index, marker)
+	}
+	return writeLayoutDir(dest, blobs, index, marker)
+}
+
+func writeLayoutTarball(dest string, created time.Time, blobs []blob, index, marker []byte) error {
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	tw := tarball.New(f, created, func(string, ...any) {})
+	dirs := map[string]struct{}{}
+	for _, b := range blobs {
+		name := b.name()
+		for _, d := range []string{blobsDir, filepath.ToSlash(filepath.Dir(name))} {
+			if _, ok := dirs[d]; ok {
+				continue
+			}
+			dirs[d] = struct{}{}
+			if err := tw.WriteDir(d); err != nil {
+				return err
+			}
+		}
+		if err := copyBlob(b, func(r io.Reader) error {
+			return tw.WriteFile(name, false, b.Size, r)
+		}); err != nil {
+			return err
+		}
+	}
+	if err := tw.WriteFile(indexFileName, false, int64(len(index)), bytes.NewReader(index)); err != nil {
+		return err
+	}
+	if err := tw.WriteFile(layoutFileName, false, int64(len(marker)), bytes.NewReader(marker)); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func writeLayoutDir(dest string, blobs []blob, index, marker []byte) error {
+	if err := cafs.MkdirEmpty(dest); err != nil {
+		return err
+	}
+	for _, b := range blobs {
+		path := filepath.Join(dest, filepath.FromSlash(b.name()))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := copyBlob(b, func(r io.Reader) error {
+			f, err := os.Create(path)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, r); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		}); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dest, indexFileName), index, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dest, layoutFileName), marker, 0o644)
+}
+
+func copyBlob(b blob, write func(io.Reader) error) error {
+	rc, err := b.open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return write(rc)
+}
+
+func descriptorFor(mediaType string, b []byte) Descriptor {
+	return Descriptor{MediaType: mediaType, Digest: digestBytes(b), Size: int64(len(b))}
+}
+
+func digestBytes(b []byte) string {
+	sum := sha256.Sum256(b)
+	return formatDigest(sum[:])
+}
+
+func formatDigest(sum []byte) string {
+	return fmt.Sprintf("sha256:%x", sum)
+}
+
+type countWriter struct{ n int64 }
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
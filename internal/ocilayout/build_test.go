@@ -0,0 +1,128 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package ocilayout
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBuild_deterministic(t *testing.T) {
+	base := createBaseLayout(t)
+	created := time.Date(2022, 7, 4, 11, 33, 33, 0, time.UTC)
+
+	var digests []string
+	modes := []os.FileMode{0o600, 0o640, 0o664}
+	for i, dest := range []string{"a.oci.tar", "b.oci.tar", "layout-dir"} {
+		files := createTargetDir(t)
+		// Different mtimes and non-executable modes on disk must not affect the image.
+		mtime := time.Now().Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(filepath.Join(files, "lockbox"), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(filepath.Join(files, "LICENSE"), modes[i]); err != nil {
+			t.Fatal(err)
+		}
+		dest := filepath.Join(t.TempDir(), dest)
+		d, err := Build(files, dest, Options{
+			BaseLayout: base,
+			OS:         "linux",
+			Arch:       "amd64",
+			Created:    created,
+			Name:       "lockbox",
+			Tag:        "1.2.3",
+			Entrypoint: "/lockbox",
+			Log:        t.Logf,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Inspect(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Digest != d.Digest {
+			t.Errorf("inspected digest %q; want %q", got.Digest, d.Digest)
+		}
+		if want := "lockbox:1.2.3"; got.Annotations[AnnotationImageName] != want {
+			t.Errorf("got image name %q; want %q", got.Annotations[AnnotationImageName], want)
+		}
+		digests = append(digests, d.Digest)
+	}
+	for _, d := range digests[1:] {
+		if d != digests[0] {
+			t.Errorf("got manifest digests %v; want all identical", digests)
+		}
+	}
+}
+
+func TestBuild_err_platform(t *testing.T) {
+	_, err := Build(createTargetDir(t), filepath.Join(t.TempDir(), "x.tar"), Options{
+		BaseLayout: createBaseLayout(t),
+		OS:         "windows",
+		Arch:       "arm64",
+		Log:        t.Logf,
+	})
+	want := "no image manifest for windows/arm64 in base layout"
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v; want %q", err, want)
+	}
+}
+
+func createTargetDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "lockbox"), []byte("#!/bin/sh\necho hi\n"), 0o755)
+	writeTestFile(t, filepath.Join(dir, "LICENSE"), []byte("MPL-2.0\n"), 0o644)
+	return dir
+}
+
+// createBaseLayout writes a minimal, layerless, linux/amd64 base image layout.
+func createBaseLayout(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeBlob := func(mediaType string, v any) Descriptor {
+		b := mustJSON(t, v)
+		d := descriptorFor(mediaType, b)
+		writeTestFile(t, filepath.Join(dir, filepath.FromSlash(blob{Descriptor: d}.name())), b, 0o644)
+		return d
+	}
+	config := writeBlob(MediaTypeImageConfig, map[string]any{
+		"architecture": "amd64",
+		"os":           "linux",
+		"rootfs":       map[string]any{"type": "layers", "diff_ids": []string{}},
+	})
+	manifest := writeBlob(MediaTypeImageManifest, Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		Config:        config,
+		Layers:        []Descriptor{},
+	})
+	manifest.Platform = &Platform{OS: "linux", Architecture: "amd64"}
+	writeTestFile(t, filepath.Join(dir, indexFileName), mustJSON(t, Index{SchemaVersion: 2, Manifests: []Descriptor{manifest}}), 0o644)
+	writeTestFile(t, filepath.Join(dir, layoutFileName), mustJSON(t, layoutMarker{layoutVersion}), 0o644)
+	return dir
+}
+
+func writeTestFile(t *testing.T, path string, contents []byte, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, contents, mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustJSON(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,161 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package ocilayout
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// layout is a read-only OCI image layout directory on disk.
+type layout struct {
+	dir string
+}
+
+func openLayout(dir string) (layout, error) {
+	l := layout{dir: dir}
+	var marker layoutMarker
+	if err := l.readJSON(layoutFileName, &marker); err != nil {
+		return l, fmt.Errorf("%q is not an OCI image layout: %w", dir, err)
+	}
+	if marker.ImageLayoutVersion != layoutVersion {
+		return l, fmt.Errorf("%q has unsupported image layout version %q", dir, marker.ImageLayoutVersion)
+	}
+	return l, nil
+}
+
+func (l layout) readJSON(name string, v any) error {
+	b, err := os.ReadFile(filepath.Join(l.dir, name))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func (l layout) blobPath(digest string) (string, error) {
+	algo, hex, ok := strings.Cut(digest, ":")
+	if !ok || algo == "" || hex == "" || strings.ContainsAny(hex, `/\.`) {
+		return "", fmt.Errorf("invalid digest %q", digest)
+	}
+	return filepath.Join(l.dir, blobsDir, algo, hex), nil
+}
+
+func (l layout) readBlob(d Descriptor) ([]byte, error) {
+	p, err := l.blobPath(d.Digest)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	if got := digestBytes(b); got != d.Digest {
+		return nil, fmt.Errorf("blob %s has digest %s", d.Digest, got)
+	}
+	return b, nil
+}
+
+func (l layout) readBlobJSON(d Descriptor, v any) error {
+	b, err := l.readBlob(d)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// resolveManifest finds the image manifest for the given platform. A layout
+// containing a single manifest is used as-is, otherwise we walk any indexes
+// looking for a manifest with a matching platform.
+func (l layout) resolveManifest(os, arch string) (Descriptor, Manifest, error) {
+	var idx Index
+	if err := l.readJSON(indexFileName, &idx); err != nil {
+		return Descriptor{}, Manifest{}, err
+	}
+	d, err := l.selectManifest(idx, os, arch)
+	if err != nil {
+		return d, Manifest{}, err
+	}
+	var m Manifest
+	if err := l.readBlobJSON(d, &m); err != nil {
+		return d, m, err
+	}
+	return d, m, nil
+}
+
+func (l layout) selectManifest(idx Index, os, arch string) (Descriptor, error) {
+	var candidates []Descriptor
+	for _, d := range idx.Manifests {
+		switch d.MediaType {
+		case MediaTypeImageIndex, mediaTypeDockerManifestList:
+			var nested Index
+			if err := l.readBlobJSON(d, &nested); err != nil {
+				return d, err
+			}
+			n, err := l.selectManifest(nested, os, arch)
+			if err != nil {
+				continue
+			}
+			candidates = append(candidates, n)
+		case MediaTypeImageManifest, mediaTypeDockerManifest:
+			if d.Platform == nil || (d.Platform.OS == os && d.Platform.Architecture == arch) {
+				candidates = append(candidates, d)
+			}
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return Descriptor{}, fmt.Errorf("no image manifest for %s/%s in base layout", os, arch)
+	case 1:
+		return candidates[0], nil
+	}
+	return Descriptor{}, fmt.Errorf("%d image manifests for %s/%s in base layout", len(candidates), os, arch)
+}
+
+// Inspect returns the descriptor of the single manifest in the layout at path,
+// which may be either a directory or a tarball written by Build.
+func Inspect(path string) (Descriptor, error) {
+	var idx Index
+	if strings.HasSuffix(path, ".tar") {
+		b, err := readFromTar(path, indexFileName)
+		if err != nil {
+			return Descriptor{}, err
+		}
+		if err := json.Unmarshal(b, &idx); err != nil {
+			return Descriptor{}, err
+		}
+	} else if err := (layout{dir: path}).readJSON(indexFileName, &idx); err != nil {
+		return Descriptor{}, err
+	}
+	if len(idx.Manifests) != 1 {
+		return Descriptor{}, fmt.Errorf("got %d manifests in %q; want 1", len(idx.Manifests), path)
+	}
+	return idx.Manifests[0], nil
+}
+
+func readFromTar(path, name string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tr := tar.NewReader(f)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s not found in %q", name, path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Name == name {
+			return io.ReadAll(tr)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package rebuilder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+
+	"github.com/hashicorp/composite-action-framework-go/pkg/json"
+)
+
+// MaxDocumentSize is the largest document that can be submitted. Real build
+// configs and results are a few kilobytes.
+const MaxDocumentSize = 16 << 20
+
+// Handler returns the service's HTTP API:
+//
+//	POST /jobs              queue a build config or build result for verification
+//	GET  /jobs              list every job
+//	GET  /jobs/{id}         get a job's status
+//	GET  /jobs/{id}/log     get a job's log
+//	GET  /jobs/{id}/result  get a finished job's verification result
+func (s *Service) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs", s.handleSubmit)
+	mux.HandleFunc("GET /jobs", s.handleList)
+	mux.HandleFunc("GET /jobs/{id}", s.handleJob)
+	mux.HandleFunc("GET /jobs/{id}/log", s.handleLog)
+	mux.HandleFunc("GET /jobs/{id}/result", s.handleResult)
+	return mux
+}
+
+// apiError is the body of every error response.
+type apiError struct {
+	Error string
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.Write(w, v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrInvalidInput):
+		status = http.StatusBadRequest
+	case errors.Is(err, ErrRejected):
+		status = http.StatusForbidden
+	case errors.As(err, &maxBytes):
+		status = http.StatusRequestEntityTooLarge
+	}
+	writeJSON(w, status, apiError{Error: err.Error()})
+}
+
+func (s *Service) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxDocumentSize))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	job, err := s.Submit(data)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Service) handleList(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.Jobs()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if jobs == nil {
+		jobs = []Job{}
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (s *Service) handleJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.Job(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *Service) handleLog(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := s.Job(id); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	s.serveFile(w, id, logFile)
+}
+
+func (s *Service) handleResult(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	job, err := s.Job(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !job.State.Finished() {
+		writeJSON(w, http.StatusConflict, apiError{Error: fmt.Sprintf("job is %s", job.State)})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	s.serveFile(w, id, resultFile)
+}
+
+// serveFile writes a file from a job's directory. Missing files are served
+// as 404s, e.g. the result of a job which failed before verifying anything.
+func (s *Service) serveFile(w http.ResponseWriter, id, name string) {
+	path, err := s.store.path(id, name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		writeJSON(w, http.StatusNotFound, apiError{Error: fmt.Sprintf("job %s has no %s", id, name)})
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer f.Close()
+	_, _ = io.Copy(w, f)
+}
@@ -0,0 +1,306 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+// Package rebuilder is a long-running service which independently verifies
+// builds submitted to it, keeping its jobs on disk so they survive restarts.
+package rebuilder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/internal/log"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+)
+
+// Input is what a job verifies: a build config, whose primary build is run
+// by the job, or the result of a primary build that's already been run.
+type Input struct {
+	Config build.Config
+	// Result is nil if a build config was submitted.
+	Result *build.Result
+}
+
+var (
+	// ErrInvalidInput is returned when a submitted document can't be verified.
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrRejected is returned when a submitted document isn't trusted.
+	ErrRejected = errors.New("rejected")
+)
+
+// OpenFunc returns the document in a submission, checking its signature if
+// it's signed. It returns an error if the submission isn't trusted.
+type OpenFunc func(data []byte) ([]byte, error)
+
+func readInput(data []byte) (Input, error) {
+	kind, _, err := build.UpgradeDocument(data)
+	if err != nil {
+		return Input{}, fmt.Errorf("%w: %s", ErrInvalidInput, err)
+	}
+	var in Input
+	switch kind {
+	case build.KindConfig:
+		in.Config, err = build.ReadDocument[build.Config](data)
+	case build.KindResult:
+		var r build.Result
+		r, err = build.ReadDocument[build.Result](data)
+		in.Config, in.Result = r.Config, &r
+	default:
+		err = fmt.Errorf("%s is not a build config or build result", kind)
+	}
+	if err != nil {
+		return Input{}, fmt.Errorf("%w: %s", ErrInvalidInput, err)
+	}
+	return in, nil
+}
+
+func (in Input) document() any {
+	if in.Result != nil {
+		return in.Result
+	}
+	return in.Config
+}
+
+func (in Input) kind() string {
+	if in.Result != nil {
+		return build.KindResult
+	}
+	return build.KindConfig
+}
+
+// RunFunc verifies a build, writing its logs and the builds' output to log.
+// It should stop when ctx is done.
+type RunFunc func(ctx context.Context, in Input, log io.Writer) (*build.VerificationResult, error)
+
+// Service queues jobs and runs them on a bounded pool of workers. Don't use
+// the zero Service, use New.
+type Service struct {
+	store   store
+	run     RunFunc
+	workers int
+	log     log.Func
+	open    OpenFunc
+
+	mu      sync.Mutex
+	wake    *sync.Cond
+	queue   []string
+	stopped bool
+	// configLocks stop jobs for the same config running at once, since
+	// they'd share build directories.
+	configLocks map[string]*sync.Mutex
+}
+
+// Option configures a Service.
+type Option func(*Service)
+
+// DefaultWorkers is how many jobs run at once by default.
+const DefaultWorkers = 2
+
+// WithWorkers sets how many jobs run at once.
+func WithWorkers(n int) Option { return func(s *Service) { s.workers = n } }
+
+// WithLog sets where the service logs.
+func WithLog(f log.Func) Option { return func(s *Service) { s.log = f } }
+
+// WithOpen sets how submissions are opened. By default, every submission is
+// trusted and read as it is, so signed submissions are invalid.
+func WithOpen(f OpenFunc) Option { return func(s *Service) { s.open = f } }
+
+// New returns a Service keeping its jobs in dir. Jobs which were queued or
+// running when the service last stopped are queued again, oldest first.
+func New(dir string, run RunFunc, opts ...Option) (*Service, error) {
+	s := &Service{
+		store:       store{dir: dir},
+		run:         run,
+		workers:     DefaultWorkers,
+		log:         func(string, ...any) {},
+		open:        func(data []byte) ([]byte, error) { return data, nil },
+		configLocks: map[string]*sync.Mutex{},
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	if s.workers < 1 {
+		s.workers = 1
+	}
+	s.wake = sync.NewCond(&s.mu)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	jobs, err := s.store.list()
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if !job.State.Finished() {
+			s.log("Resuming %s job %s", job.State, job.ID)
+			s.queue = append(s.queue, job.ID)
+		}
+	}
+	return s, nil
+}
+
+// Submit queues a job to verify a build config or build result document.
+// It returns an error wrapping ErrRejected if the service's OpenFunc doesn't
+// trust it, or ErrInvalidInput if the document isn't one.
+func (s *Service) Submit(data []byte) (Job, error) {
+	data, err := s.open(data)
+	if err != nil {
+		return Job{}, fmt.Errorf("%w: %w", ErrRejected, err)
+	}
+	in, err := readInput(data)
+	if err != nil {
+		return Job{}, err
+	}
+	c := in.Config
+	if c.Product.IsDirty() {
+		return Job{}, fmt.Errorf("%w: dirty builds can't be verified remotely", ErrInvalidInput)
+	}
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+	job := Job{
+		ID:        id,
+		State:     StateQueued,
+		Kind:      in.kind(),
+		ConfigID:  c.ID(),
+		Product:   c.Product.Name,
+		Version:   c.Product.Version.Full,
+		Platform:  c.Parameters.OS + "/" + c.Parameters.Arch,
+		Submitted: time.Now().UTC(),
+	}
+	if err := s.store.create(job, in.document()); err != nil {
+		return Job{}, err
+	}
+	s.log("Queued job %s: %s %s %s", job.ID, job.Product, job.Version, job.Platform)
+	s.mu.Lock()
+	s.queue = append(s.queue, job.ID)
+	s.mu.Unlock()
+	s.wake.Signal()
+	return job, nil
+}
+
+// Job returns the job with id.
+func (s *Service) Job(id string) (Job, error) { return s.store.load(id) }
+
+// Jobs returns every job, oldest first.
+func (s *Service) Jobs() ([]Job, error) { return s.store.list() }
+
+// Run runs queued jobs until ctx is done, then waits for running jobs to
+// stop. Jobs interrupted this way are resumed by the next Service using the
+// same directory.
+func (s *Service) Run(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		s.stopped = true
+		s.mu.Unlock()
+		s.wake.Broadcast()
+	}()
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				id, ok := s.next(ctx)
+				if !ok {
+					return
+				}
+				if err := s.runJob(ctx, id); err != nil {
+					s.log("Job %s: %s", id, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// next waits for a queued job, returning false once the service has stopped.
+func (s *Service) next(ctx context.Context) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.queue) == 0 && !s.stopped {
+		s.wake.Wait()
+	}
+	// ctx may be done before stopped is set.
+	if s.stopped || ctx.Err() != nil {
+		return "", false
+	}
+	id := s.queue[0]
+	s.queue = s.queue[1:]
+	return id, true
+}
+
+func (s *Service) configLock(id string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.configLocks[id]
+	if !ok {
+		l = &sync.Mutex{}
+		s.configLocks[id] = l
+	}
+	return l
+}
+
+func (s *Service) runJob(ctx context.Context, id string) error {
+	job, err := s.store.load(id)
+	if err != nil {
+		return err
+	}
+	in, err := s.store.input(id)
+	if err != nil {
+		return err
+	}
+	lock := s.configLock(job.ConfigID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	logw, err := s.store.openLog(id)
+	if err != nil {
+		return err
+	}
+	defer logw.Close()
+	if job.State == StateRunning {
+		fmt.Fprintf(logw, "Resuming job interrupted by a restart.\n")
+	}
+	started := time.Now().UTC()
+	job.State, job.Started = StateRunning, &started
+	if err := s.store.save(job); err != nil {
+		return err
+	}
+	s.log("Running job %s", id)
+
+	vr, err := s.run(ctx, in, logw)
+	if ctx.Err() != nil {
+		// Leave the job running, so that it's resumed after a restart.
+		fmt.Fprintf(logw, "Interrupted: %s\n", ctx.Err())
+		return nil
+	}
+	finished := time.Now().UTC()
+	job.Finished = &finished
+	switch {
+	case err != nil:
+		job.State, job.Error = StateFailed, err.Error()
+	case vr == nil:
+		job.State, job.Error = StateFailed, "no verification result"
+	case vr.ReproducedCorrectly:
+		job.State = StateReproduced
+	default:
+		job.State, job.Error = StateNotReproduced, vr.ErrorMessage
+	}
+	if vr != nil {
+		if err := s.store.saveResult(id, vr); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(logw, "Finished: %s\n", job.State)
+	s.log("Job %s %s", id, job.State)
+	return s.store.save(job)
+}
@@ -0,0 +1,212 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package rebuilder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/internal/testhelpers/buildtest"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+// reproduce is a RunFunc which reproduces every build.
+func reproduce(_ context.Context, in Input, w io.Writer) (*build.VerificationResult, error) {
+	fmt.Fprintf(w, "verifying %s\n", in.Config.Product.Name)
+	r := build.Result{Config: in.Config, Successful: true}
+	return &build.VerificationResult{Primary: &r, Verification: &r, ReproducedCorrectly: true}, nil
+}
+
+func do(t *testing.T, h http.Handler, method, path string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewReader(body)))
+	return w
+}
+
+func waitFinished(t *testing.T, s *Service, id string) Job {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := s.Job(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.State.Finished() {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s didn't finish", id)
+	return Job{}
+}
+
+func TestService_http(t *testing.T) {
+	s, err := New(tmp.Dir(t), reproduce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	h := s.Handler()
+
+	doc, err := build.MarshalDocument(buildtest.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := do(t, h, "POST", "/jobs", doc)
+	if resp.Code != http.StatusAccepted {
+		t.Fatalf("got status %d; want %d: %s", resp.Code, http.StatusAccepted, resp.Body)
+	}
+	var job Job
+	if err := json.Unmarshal(resp.Body.Bytes(), &job); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resp.Header().Get("Location"), "/jobs/"+job.ID; got != want {
+		t.Errorf("got Location %q; want %q", got, want)
+	}
+	if job.Kind != build.KindConfig || job.Platform != "linux/amd64" {
+		t.Errorf("got job %+v; want a linux/amd64 BuildConfig job", job)
+	}
+
+	if got := waitFinished(t, s, job.ID); got.State != StateReproduced {
+		t.Errorf("got state %q; want %q", got.State, StateReproduced)
+	}
+	if resp := do(t, h, "GET", "/jobs/"+job.ID+"/log", nil); resp.Body.String() != "verifying lockbox\nFinished: reproduced\n" {
+		t.Errorf("got log %q", resp.Body)
+	}
+	resp = do(t, h, "GET", "/jobs/"+job.ID+"/result", nil)
+	vr, err := build.ReadDocument[build.VerificationResult](resp.Body.Bytes())
+	if err != nil {
+		t.Fatalf("reading result: %s\n%s", err, resp.Body)
+	}
+	if !vr.ReproducedCorrectly {
+		t.Errorf("got ReproducedCorrectly = false; want true")
+	}
+	if resp := do(t, h, "GET", "/jobs", nil); !bytes.Contains(resp.Body.Bytes(), []byte(job.ID)) {
+		t.Errorf("got job list %s; want it to contain %s", resp.Body, job.ID)
+	}
+}
+
+func TestService_http_errors(t *testing.T) {
+	s, err := New(tmp.Dir(t), reproduce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := s.Handler()
+	queued, err := s.Submit(mustMarshal(t, buildtest.Config()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dirty := buildtest.Config()
+	dirty.Product.SourceHash = "d1rty"
+	cases := []struct {
+		desc, method, path string
+		body               []byte
+		want               int
+	}{
+		{"not a document", "POST", "/jobs", []byte(`{"foo": "bar"}`), http.StatusBadRequest},
+		{"verification result", "POST", "/jobs", mustMarshal(t, build.VerificationResult{}), http.StatusBadRequest},
+		{"dirty", "POST", "/jobs", mustMarshal(t, dirty), http.StatusBadRequest},
+		{"unknown job", "GET", "/jobs/0123456789abcdef", nil, http.StatusNotFound},
+		{"invalid id", "GET", "/jobs/..%2f..%2fetc/log", nil, http.StatusNotFound},
+		{"unfinished result", "GET", "/jobs/" + queued.ID + "/result", nil, http.StatusConflict},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			if resp := do(t, h, c.method, c.path, c.body); resp.Code != c.want {
+				t.Errorf("got status %d; want %d: %s", resp.Code, c.want, resp.Body)
+			}
+		})
+	}
+}
+
+func TestService_http_rejected(t *testing.T) {
+	open := func(data []byte) ([]byte, error) {
+		if !bytes.HasPrefix(data, []byte("signed:")) {
+			return nil, fmt.Errorf("not signed")
+		}
+		return bytes.TrimPrefix(data, []byte("signed:")), nil
+	}
+	s, err := New(tmp.Dir(t), reproduce, WithOpen(open))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := s.Handler()
+	doc := mustMarshal(t, buildtest.Config())
+	if resp := do(t, h, "POST", "/jobs", doc); resp.Code != http.StatusForbidden {
+		t.Errorf("got status %d for an unsigned document; want %d: %s", resp.Code, http.StatusForbidden, resp.Body)
+	}
+	if resp := do(t, h, "POST", "/jobs", append([]byte("signed:"), doc...)); resp.Code != http.StatusAccepted {
+		t.Errorf("got status %d for a signed document; want %d: %s", resp.Code, http.StatusAccepted, resp.Body)
+	}
+}
+
+// TestService_restart checks jobs left queued or running by a previous
+// service are run by the next one.
+func TestService_restart(t *testing.T) {
+	dir := tmp.Dir(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	block := func(ctx context.Context, in Input, w io.Writer) (*build.VerificationResult, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	s, err := New(dir, block, WithWorkers(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	running, err := s.Submit(mustMarshal(t, buildtest.Config()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	queued, err := s.Submit(mustMarshal(t, build.Result{Config: buildtest.Config(), Successful: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	<-started
+	cancel()
+	<-done
+	if job, _ := s.Job(running.ID); job.State != StateRunning {
+		t.Fatalf("got interrupted job state %q; want %q", job.State, StateRunning)
+	}
+
+	s, err = New(dir, reproduce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	for _, id := range []string{running.ID, queued.ID} {
+		if job := waitFinished(t, s, id); job.State != StateReproduced {
+			t.Errorf("job %s: got state %q; want %q", id, job.State, StateReproduced)
+		}
+	}
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := build.MarshalDocument(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,190 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package rebuilder
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/internal/atomicfile"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	"github.com/hashicorp/composite-action-framework-go/pkg/json"
+)
+
+// State is the state of a job.
+type State string
+
+const (
+	// StateQueued jobs are waiting for a worker.
+	StateQueued State = "queued"
+	// StateRunning jobs are being verified by a worker.
+	StateRunning State = "running"
+	// StateReproduced jobs finished, and the verification build reproduced
+	// the primary build.
+	StateReproduced State = "reproduced"
+	// StateNotReproduced jobs finished, but the verification build didn't
+	// reproduce the primary build.
+	StateNotReproduced State = "not reproduced"
+	// StateFailed jobs couldn't be verified, e.g. because a build failed.
+	StateFailed State = "failed"
+)
+
+// Finished returns true if s is a final state.
+func (s State) Finished() bool { return s != StateQueued && s != StateRunning }
+
+// Job is a request to verify a build.
+type Job struct {
+	ID    string
+	State State
+	// Kind is the kind of document submitted: a build config or build result.
+	Kind     string
+	ConfigID string
+	Product  string
+	Version  string
+	Platform string
+
+	Submitted time.Time
+	Started   *time.Time `json:",omitempty"`
+	Finished  *time.Time `json:",omitempty"`
+	// Error is why the job failed or didn't reproduce.
+	Error string `json:",omitempty"`
+}
+
+// ErrNotFound is returned for jobs that don't exist.
+var ErrNotFound = errors.New("job not found")
+
+// File names within each job's directory.
+const (
+	jobFile    = "job.json"
+	inputFile  = "input.json"
+	logFile    = "log.txt"
+	resultFile = "result.json"
+)
+
+var validID = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// store keeps each job in its own directory, so that jobs survive restarts.
+type store struct {
+	dir string
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// path returns the path to a file in a job's directory. IDs come from
+// clients, so they're checked before being used in paths.
+func (s store) path(id, name string) (string, error) {
+	if !validID.MatchString(id) {
+		return "", ErrNotFound
+	}
+	return filepath.Join(s.dir, id, name), nil
+}
+
+func (s store) create(job Job, input any) error {
+	if err := os.MkdirAll(filepath.Join(s.dir, job.ID), 0o755); err != nil {
+		return err
+	}
+	path, err := s.path(job.ID, inputFile)
+	if err != nil {
+		return err
+	}
+	if err := build.WriteDocumentFile(path, input); err != nil {
+		return err
+	}
+	// Create the log up front, so it can be read while the job is queued.
+	f, err := s.openLog(job.ID)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return s.save(job)
+}
+
+func (s store) save(job Job) error {
+	path, err := s.path(job.ID, jobFile)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteJSON(path, job)
+}
+
+func (s store) load(id string) (Job, error) {
+	path, err := s.path(id, jobFile)
+	if err != nil {
+		return Job{}, err
+	}
+	job, err := json.ReadFile[Job](path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Job{}, ErrNotFound
+	}
+	return job, err
+}
+
+// list returns every job, oldest first.
+func (s store) list() ([]Job, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []Job
+	for _, e := range entries {
+		if !e.IsDir() || !validID.MatchString(e.Name()) {
+			continue
+		}
+		job, err := s.load(e.Name())
+		if errors.Is(err, ErrNotFound) {
+			// The service stopped while the job was being created.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("loading job %s: %w", e.Name(), err)
+		}
+		jobs = append(jobs, job)
+	}
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].Submitted.Before(jobs[j].Submitted) })
+	return jobs, nil
+}
+
+func (s store) input(id string) (Input, error) {
+	path, err := s.path(id, inputFile)
+	if err != nil {
+		return Input{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Input{}, err
+	}
+	return readInput(data)
+}
+
+func (s store) openLog(id string) (*os.File, error) {
+	path, err := s.path(id, logFile)
+	if err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+}
+
+func (s store) saveResult(id string, vr *build.VerificationResult) error {
+	path, err := s.path(id, resultFile)
+	if err != nil {
+		return err
+	}
+	return build.WriteDocumentFile(path, vr)
+}
@@ -0,0 +1,99 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/composite-action-framework-go/pkg/json"
+)
+
+// Formats a report can be rendered in.
+const (
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+	FormatHTML     = "html"
+)
+
+// Formats lists every format a report can be rendered in.
+var Formats = []string{FormatMarkdown, FormatJSON, FormatHTML}
+
+//go:embed templates/report.md.tmpl
+var markdownTemplate string
+
+//go:embed templates/report.html.tmpl
+var htmlTemplate string
+
+// hashPair is a hash from the primary build and, if it's been verified, the
+// same hash from the verification build.
+type hashPair struct {
+	Primary, Verification string
+}
+
+var funcs = map[string]any{
+	"summary":  Report.Summary,
+	"yesNo":    yesNo,
+	"hashPair": func(p, v string) hashPair { return hashPair{p, v} },
+}
+
+// Write renders the report to w in format.
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatMarkdown:
+		return r.Markdown(w)
+	case FormatJSON:
+		return r.JSON(w)
+	case FormatHTML:
+		return r.HTML(w)
+	}
+	return fmt.Errorf("unknown format %q; must be one of %s", format, strings.Join(Formats, ", "))
+}
+
+// Markdown renders the report as markdown, suitable for a GitHub step summary.
+func (r Report) Markdown(w io.Writer) error {
+	t := template.Must(template.New("").Funcs(funcs).Parse(markdownTemplate))
+	return t.Execute(w, r)
+}
+
+// HTML renders the report as a self-contained HTML page.
+func (r Report) HTML(w io.Writer) error {
+	t := htmltemplate.Must(htmltemplate.New("").Funcs(funcs).Parse(htmlTemplate))
+	return t.Execute(w, r)
+}
+
+// JSON renders the report as JSON.
+func (r Report) JSON(w io.Writer) error {
+	return json.Write(w, r)
+}
+
+// Summary returns a one line summary of the report, e.g.
+// "5 builds: 4 reproduced, 1 not reproduced".
+func (r Report) Summary() string {
+	var parts []string
+	for _, s := range []Status{StatusReproduced, StatusNotReproduced, StatusBuilt, StatusFailed} {
+		if n := r.Counts[s]; n != 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, s))
+		}
+	}
+	noun := "builds"
+	if r.Total == 1 {
+		noun = "build"
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("%d %s", r.Total, noun)
+	}
+	return fmt.Sprintf("%d %s: %s", r.Total, noun, strings.Join(parts, ", "))
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,241 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+// Package report summarises many build and verification results, e.g. one
+// for each platform a release is built for.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/actions-go-build/internal/signing"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+)
+
+// Status is the outcome of a single build or verification.
+type Status string
+
+const (
+	// StatusReproduced means the verification build reproduced the primary build.
+	StatusReproduced Status = "reproduced"
+	// StatusNotReproduced means the verification build didn't reproduce the
+	// primary build.
+	StatusNotReproduced Status = "not reproduced"
+	// StatusBuilt means the build succeeded, but hasn't been verified.
+	StatusBuilt Status = "built"
+	// StatusFailed means the build failed.
+	StatusFailed Status = "failed"
+)
+
+// OK returns true if s isn't a failure.
+func (s Status) OK() bool { return s == StatusReproduced || s == StatusBuilt }
+
+// Row describes one build or verification.
+type Row struct {
+	// File is the file this row was read from.
+	File     string
+	Product  string
+	Version  string
+	Platform string
+	Status   Status
+	// Dirty is true if either build was of a dirty worktree.
+	Dirty bool
+	// Cached is true if the verification run loaded the primary build
+	// result from the cache. It's always false for build results.
+	Cached  bool
+	ZipName string
+	// ZipSHA256 and BinSHA256 are from the primary build.
+	ZipSHA256 string
+	BinSHA256 string
+	// VerificationZipSHA256 and VerificationBinSHA256 are empty if the
+	// build hasn't been verified.
+	VerificationZipSHA256 string `json:",omitempty"`
+	VerificationBinSHA256 string `json:",omitempty"`
+	Duration              string
+	VerificationDuration  string `json:",omitempty"`
+	Error                 string `json:",omitempty"`
+}
+
+// Product groups the rows for a single product version.
+type Product struct {
+	Name, Version string
+	Rows          []Row
+}
+
+// Report summarises many builds and verifications.
+type Report struct {
+	Products []Product
+	// Counts is the number of rows with each status.
+	Counts map[Status]int
+	// Total is the total number of rows.
+	Total int
+	// Skipped lists files found in directories which aren't trusted build
+	// or verification results.
+	Skipped []string `json:",omitempty"`
+}
+
+// OK returns true if no row has a failing status.
+func (r Report) OK() bool {
+	return r.Counts[StatusFailed] == 0 && r.Counts[StatusNotReproduced] == 0
+}
+
+// Load reads build and verification results from paths, opening each one
+// with trust. Each path may be a file containing a result or a list of
+// results, or a directory which is searched for .json files containing them.
+func Load(trust signing.Trust, paths ...string) (Report, error) {
+	var rows []Row
+	var skipped []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return Report{}, err
+		}
+		if !info.IsDir() {
+			r, err := readFile(trust, path)
+			if err != nil {
+				return Report{}, err
+			}
+			rows = append(rows, r...)
+			continue
+		}
+		err = filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+				return err
+			}
+			r, err := readFile(trust, path)
+			if err != nil {
+				skipped = append(skipped, path)
+				return nil
+			}
+			rows = append(rows, r...)
+			return nil
+		})
+		if err != nil {
+			return Report{}, err
+		}
+	}
+	return New(rows, skipped), nil
+}
+
+// New returns a report of rows, grouped by product and sorted by platform.
+func New(rows []Row, skipped []string) Report {
+	r := Report{Counts: map[Status]int{}, Total: len(rows), Skipped: skipped}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Product != b.Product {
+			return a.Product < b.Product
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Platform < b.Platform
+	})
+	for _, row := range rows {
+		r.Counts[row.Status]++
+		if n := len(r.Products); n == 0 || r.Products[n-1].Name != row.Product || r.Products[n-1].Version != row.Version {
+			r.Products = append(r.Products, Product{Name: row.Product, Version: row.Version})
+		}
+		p := &r.Products[len(r.Products)-1]
+		p.Rows = append(p.Rows, row)
+	}
+	return r
+}
+
+// readFile reads the results in the file at path, which may contain a
+// single document or a list of them, any of which may be signed.
+func readFile(trust signing.Trust, path string) ([]Row, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	docs := []json.RawMessage{data}
+	list := strings.HasPrefix(strings.TrimSpace(string(data)), "[")
+	if list {
+		if err := json.Unmarshal(data, &docs); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+	rows := make([]Row, len(docs))
+	for i, doc := range docs {
+		location := path
+		if list {
+			location = fmt.Sprintf("%s[%d]", path, i)
+		}
+		if doc, err = trust.Open(location, doc); err != nil {
+			return nil, err
+		}
+		if rows[i], err = readRow(doc); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		rows[i].File = path
+	}
+	return rows, nil
+}
+
+func readRow(data []byte) (Row, error) {
+	kind, _, err := build.UpgradeDocument(data)
+	if err != nil {
+		return Row{}, err
+	}
+	switch kind {
+	case build.KindResult:
+		r, err := build.ReadDocument[build.Result](data)
+		if err != nil {
+			return Row{}, err
+		}
+		return resultRow(r), nil
+	case build.KindVerificationResult:
+		vr, err := build.ReadDocument[build.VerificationResult](data)
+		if err != nil {
+			return Row{}, err
+		}
+		return verificationRow(vr)
+	}
+	return Row{}, fmt.Errorf("%s is not a build result or verification result", kind)
+}
+
+func resultRow(r build.Result) Row {
+	c := r.Config
+	row := Row{
+		Product:   c.Product.Name,
+		Version:   c.Product.Version.Full,
+		Platform:  c.Parameters.OS + "/" + c.Parameters.Arch,
+		Status:    StatusBuilt,
+		Dirty:     c.Product.IsDirty(),
+		ZipName:   c.Parameters.ZipName,
+		ZipSHA256: r.Zip.SHA256Sum,
+		BinSHA256: r.Executable.SHA256Sum,
+		Duration:  r.Meta.Duration,
+		Error:     r.ErrorMessage,
+	}
+	if !r.Successful {
+		row.Status = StatusFailed
+	}
+	return row
+}
+
+func verificationRow(vr build.VerificationResult) (Row, error) {
+	if vr.Primary == nil {
+		return Row{}, fmt.Errorf("verification result has no primary build result")
+	}
+	row := resultRow(*vr.Primary)
+	row.Status = StatusReproduced
+	if !vr.ReproducedCorrectly {
+		row.Status = StatusNotReproduced
+	}
+	row.Dirty = vr.Dirty
+	row.Cached = vr.PrimaryFromCache
+	row.Error = vr.ErrorMessage
+	if v := vr.Verification; v != nil {
+		row.VerificationZipSHA256 = v.Zip.SHA256Sum
+		row.VerificationBinSHA256 = v.Executable.SHA256Sum
+		row.VerificationDuration = v.Meta.Duration
+	}
+	return row, nil
+}
@@ -0,0 +1,179 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package report
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/actions-go-build/internal/signing"
+	"github.com/hashicorp/actions-go-build/internal/testhelpers/buildtest"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+// platformResult returns a result for a build for os/arch with the zip hash
+// zipSum.
+func platformResult(os, arch, zipSum string) *build.Result {
+	r := buildtest.Result()
+	r.Config.Parameters.OS = os
+	r.Config.Parameters.Arch = arch
+	r.Zip.SHA256Sum = zipSum
+	r.Meta.Duration = "1m0s"
+	return &r
+}
+
+func TestLoad(t *testing.T) {
+	dir := tmp.Dir(t)
+	reproduced := &build.VerificationResult{
+		Primary:             platformResult("linux", "amd64", "aaaa"),
+		Verification:        platformResult("linux", "amd64", "aaaa"),
+		ReproducedCorrectly: true,
+		PrimaryFromCache:    true,
+	}
+	notReproduced := &build.VerificationResult{
+		Primary:      platformResult("linux", "arm64", "bbbb"),
+		Verification: platformResult("linux", "arm64", "cccc"),
+		ErrorMessage: "zip files differ",
+	}
+	buildtest.WriteDocument(t, filepath.Join(dir, "linux.json"), []*build.VerificationResult{reproduced, notReproduced})
+	buildtest.WriteDocument(t, filepath.Join(dir, "darwin.json"), platformResult("darwin", "arm64", "dddd"))
+	if err := os.WriteFile(filepath.Join(dir, "other.json"), []byte(`{"foo": "bar"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Load(signing.Trust{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Products) != 1 {
+		t.Fatalf("got %d products; want 1", len(r.Products))
+	}
+	var got []string
+	for _, row := range r.Products[0].Rows {
+		got = append(got, row.Platform+" "+string(row.Status))
+	}
+	want := []string{"darwin/arm64 built", "linux/amd64 reproduced", "linux/arm64 not reproduced"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("got rows %q; want %q", got, want)
+	}
+	for i, want := range []bool{false, true, false} {
+		if got := r.Products[0].Rows[i].Cached; got != want {
+			t.Errorf("%s: got Cached = %t; want %t", r.Products[0].Rows[i].Platform, got, want)
+		}
+	}
+	if r.OK() {
+		t.Errorf("got OK() = true; want false")
+	}
+	if len(r.Skipped) != 1 || filepath.Base(r.Skipped[0]) != "other.json" {
+		t.Errorf("got skipped %q; want other.json", r.Skipped)
+	}
+	if got, want := r.Summary(), "3 builds: 1 reproduced, 1 not reproduced, 1 built"; got != want {
+		t.Errorf("got summary %q; want %q", got, want)
+	}
+}
+
+func TestLoad_explicitFileMustBeAResult(t *testing.T) {
+	path := filepath.Join(tmp.Dir(t), "other.json")
+	if err := os.WriteFile(path, []byte(`{"foo": "bar"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(signing.Trust{}, path); err == nil {
+		t.Fatal("got nil error; want an error")
+	}
+}
+
+func TestLoad_signed(t *testing.T) {
+	dir := tmp.Dir(t)
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(v any) []byte {
+		doc, err := build.MarshalDocument(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed, err := json.Marshal(signing.Sign(key, doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	list, err := json.Marshal([]json.RawMessage{sign(platformResult("linux", "amd64", "aaaa"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"darwin.json": sign(platformResult("darwin", "arm64", "bbbb")),
+		"linux.json":  list,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	trust := signing.Trust{Keys: []ed25519.PublicKey{key.Public().(ed25519.PublicKey)}, RequireSigned: true}
+
+	r, err := Load(trust, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Total != 2 || len(r.Skipped) != 0 {
+		t.Errorf("got %d rows, skipped %q; want 2 rows, none skipped", r.Total, r.Skipped)
+	}
+
+	unsigned := filepath.Join(dir, "unsigned.json")
+	buildtest.WriteDocument(t, unsigned, platformResult("windows", "amd64", "cccc"))
+	if _, err := Load(trust, unsigned); err == nil {
+		t.Error("got nil error loading an unsigned result; want an error")
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	r := New([]Row{
+		{Product: "lockbox", Version: "1.2.3", Platform: "linux/amd64", Status: StatusReproduced, Cached: true, ZipSHA256: "aaaa", VerificationZipSHA256: "aaaa"},
+		{Product: "lockbox", Version: "1.2.3", Platform: "linux/arm64", Status: StatusNotReproduced, ZipSHA256: "bbbb", VerificationZipSHA256: "cccc", Error: "<zip files differ>"},
+	}, nil)
+	cases := []struct {
+		format string
+		want   []string
+	}{
+		{FormatMarkdown, []string{
+			"## :x: Build report: 2 builds: 1 reproduced, 1 not reproduced",
+			"| linux/amd64 | :white_check_mark: reproduced | no | yes | `aaaa` |",
+			"primary `bbbb`<br>verification `cccc`",
+			"- `linux/arm64`: <zip files differ>",
+		}},
+		{FormatHTML, []string{
+			"<title>Build report: 2 builds: 1 reproduced, 1 not reproduced</title>",
+			"<code>aaaa</code>",
+			"&lt;zip files differ&gt;",
+		}},
+		{FormatJSON, []string{`"Platform": "linux/arm64"`, `"not reproduced": 1`}},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := r.Write(&buf, c.format); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range c.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("got:\n%s\nwant it to contain %q", buf.String(), want)
+				}
+			}
+		})
+	}
+	if err := r.Write(&bytes.Buffer{}, "pdf"); err == nil {
+		t.Errorf("got nil error for unknown format; want an error")
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+// Package signing signs build configs, build results, and verification
+// results by wrapping them in DSSE envelopes, and verifies those envelopes
+// against a set of trusted ed25519 keys.
+//
+// See https://github.com/secure-systems-lab/dsse for the envelope format.
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// PayloadType is the payload type of envelopes containing documents.
+const PayloadType = "application/vnd.hashicorp.actions-go-build+json"
+
+// Envelope is a DSSE envelope.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     []byte      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a signature of an envelope's payload.
+type Signature struct {
+	// KeyID is the KeyID of the key the payload was signed with.
+	KeyID string `json:"keyid"`
+	Sig   []byte `json:"sig"`
+}
+
+// pae returns the DSSE pre-authentication encoding of payload, which is
+// what's actually signed, so that the payload type is signed too.
+func pae(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+// Sign returns an envelope containing payload, signed with key.
+func Sign(key ed25519.PrivateKey, payload []byte) Envelope {
+	return Envelope{
+		PayloadType: PayloadType,
+		Payload:     payload,
+		Signatures: []Signature{{
+			KeyID: KeyID(key.Public().(ed25519.PublicKey)),
+			Sig:   ed25519.Sign(key, pae(PayloadType, payload)),
+		}},
+	}
+}
+
+// IsEnvelope returns true if data looks like a DSSE envelope rather than a
+// document. It doesn't check the envelope is valid.
+func IsEnvelope(data []byte) bool {
+	var probe struct {
+		PayloadType *string `json:"payloadType"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	return dec.Decode(&probe) == nil && probe.PayloadType != nil
+}
+
+// ErrUntrusted is returned when an envelope isn't signed by a trusted key.
+var ErrUntrusted = errors.New("not signed by a trusted key")
+
+// Open returns the payload of the envelope in data, and the KeyID of the
+// trusted key it was signed with.
+func Open(data []byte, trusted ...ed25519.PublicKey) (payload []byte, keyID string, err error) {
+	var e Envelope
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, "", fmt.Errorf("reading envelope: %w", err)
+	}
+	if e.PayloadType != PayloadType {
+		return nil, "", fmt.Errorf("envelope has payload type %q, want %q", e.PayloadType, PayloadType)
+	}
+	msg := pae(e.PayloadType, e.Payload)
+	for _, s := range e.Signatures {
+		for _, pub := range trusted {
+			if ed25519.Verify(pub, msg, s.Sig) {
+				return e.Payload, KeyID(pub), nil
+			}
+		}
+	}
+	return nil, "", ErrUntrusted
+}
+
+// Payload returns the payload of the envelope in data without checking its
+// signatures.
+func Payload(data []byte) ([]byte, error) {
+	var e Envelope
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("reading envelope: %w", err)
+	}
+	return e.Payload, nil
+}
@@ -0,0 +1,94 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+func newKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func marshal(t *testing.T, e Envelope) []byte {
+	t.Helper()
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestOpen(t *testing.T) {
+	key, other := newKey(t), newKey(t)
+	pub := key.Public().(ed25519.PublicKey)
+	otherPub := other.Public().(ed25519.PublicKey)
+	payload := []byte(`{"Kind": "BuildResult"}`)
+	e := Sign(key, payload)
+	data := marshal(t, e)
+
+	if !IsEnvelope(data) {
+		t.Fatal("IsEnvelope = false for an envelope")
+	}
+	if IsEnvelope(payload) {
+		t.Fatal("IsEnvelope = true for a document")
+	}
+
+	got, keyID, err := Open(data, otherPub, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(payload) || keyID != KeyID(pub) {
+		t.Errorf("got %q signed by %s; want %q signed by %s", got, keyID, payload, KeyID(pub))
+	}
+
+	if _, _, err := Open(data, otherPub); !errors.Is(err, ErrUntrusted) {
+		t.Errorf("got %v opening with the wrong key; want ErrUntrusted", err)
+	}
+	tampered := e
+	tampered.Payload = []byte(`{"Kind": "BuildConfig"}`)
+	if _, _, err := Open(marshal(t, tampered), pub); !errors.Is(err, ErrUntrusted) {
+		t.Errorf("got %v opening a tampered payload; want ErrUntrusted", err)
+	}
+	tampered = e
+	tampered.PayloadType = "text/plain"
+	if _, _, err := Open(marshal(t, tampered), pub); err == nil {
+		t.Error("got nil error opening an envelope with the wrong payload type")
+	}
+}
+
+func TestKeyFiles(t *testing.T) {
+	dir := tmp.Dir(t)
+	key := newKey(t)
+	privPath, pubPath := filepath.Join(dir, "key.pem"), filepath.Join(dir, "key.pub")
+	if err := WriteKeyFiles(key, privPath, pubPath); err != nil {
+		t.Fatal(err)
+	}
+	gotKey, err := ReadPrivateKeyFile(privPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotPub, err := ReadPublicKeyFile(pubPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gotKey.Equal(key) || !gotPub.Equal(key.Public()) {
+		t.Error("keys changed")
+	}
+	if _, err := ReadPublicKeyFile(privPath); err == nil {
+		t.Error("got nil error reading a private key as a public key")
+	}
+}
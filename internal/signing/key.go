@@ -0,0 +1,91 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// ReadPublicKeyFile reads a PEM encoded ed25519 public key, as written by
+// openssl pkey -pubout.
+func ReadPublicKeyFile(path string) (ed25519.PublicKey, error) {
+	der, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("reading %s: not an ed25519 public key", path)
+	}
+	return pub, nil
+}
+
+// ReadPrivateKeyFile reads a PEM encoded PKCS #8 ed25519 private key, as
+// written by openssl genpkey -algorithm ed25519.
+func ReadPrivateKeyFile(path string) (ed25519.PrivateKey, error) {
+	der, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("reading %s: not an ed25519 private key", path)
+	}
+	return priv, nil
+}
+
+func readPEM(path, blockType string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("reading %s: no PEM encoded %s", path, blockType)
+	}
+	return block.Bytes, nil
+}
+
+// WriteKeyFiles writes priv to privPath, readable only by its owner, and its
+// public key to pubPath.
+func WriteKeyFiles(priv ed25519.PrivateKey, privPath, pubPath string) error {
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return err
+	}
+	der, err = x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o644)
+}
+
+// KeyID returns the hex SHA-256 of the DER encoding of pub, which identifies
+// the key in signatures.
+func KeyID(pub ed25519.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		// Marshalling an ed25519 public key can't fail.
+		panic(err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,57 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package signing
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/actions-go-build/internal/log"
+)
+
+// Trust decides which documents to trust, and reads the documents inside
+// signed envelopes. The zero Trust accepts every document, and reads
+// signed ones without checking their signatures.
+type Trust struct {
+	// Keys are the keys signatures are checked against. Without any,
+	// signatures aren't checked.
+	Keys []ed25519.PublicKey
+	// RequireSigned refuses documents which aren't signed with one of Keys.
+	RequireSigned bool
+	// Log is told which key each document was signed with, and Warn is
+	// told when a signature isn't checked. Either may be nil.
+	Log, Warn log.Func
+}
+
+// Open returns the document in data, read from location. If data is a
+// signed envelope, its signature is checked against the trusted keys, and
+// its payload is returned. Unsigned documents are returned as they are,
+// unless a signature is required.
+func (t Trust) Open(location string, data []byte) ([]byte, error) {
+	if !IsEnvelope(data) {
+		if t.RequireSigned {
+			return nil, fmt.Errorf("%s isn't signed", location)
+		}
+		return data, nil
+	}
+	if len(t.Keys) == 0 {
+		// Nothing to check the signature against, so it can't be required.
+		if t.Warn != nil {
+			t.Warn("Not checking the signature on %s; pass -trusted-key to check it.", location)
+		}
+		return Payload(data)
+	}
+	payload, keyID, err := Open(data, t.Keys...)
+	if errors.Is(err, ErrUntrusted) {
+		return nil, fmt.Errorf("%s is %w", location, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", location, err)
+	}
+	if t.Log != nil {
+		t.Log("%s is signed by trusted key %s", location, keyID)
+	}
+	return payload, nil
+}
@@ -0,0 +1,141 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/actions-go-build/internal/fetch"
+	"github.com/hashicorp/actions-go-build/internal/log"
+)
+
+// Cloner clones git repositories using the git CLI, so that submodules,
+// LFS objects (if git-lfs is installed), and local or file:// repositories
+// all work just as they do for developers.
+type Cloner struct {
+	Context context.Context
+	// Token, if set, is sent to the host of the URL being cloned only.
+	Token  fetch.Token
+	Log    log.Func
+	Stderr io.Writer
+}
+
+// Clone clones the repository at url into dir, checks out rev and all
+// submodules recursively, and returns the tree hash of rev.
+func (c Cloner) Clone(url, rev, dir string) (string, error) {
+	env := c.tokenEnv(url)
+	clone := []string{"clone", "--no-checkout", "--no-recurse-submodules", "--", url, dir}
+	if isLocal(url) {
+		// Allow cloning a local superproject even if file:// is restricted.
+		// This is only passed to the clone itself: git refuses local
+		// submodules by default, since a repository from elsewhere could use
+		// them to read other repositories on this machine (CVE-2022-39253).
+		clone = append([]string{"-c", "protocol.file.allow=always"}, clone...)
+	}
+	if err := c.git(env, "", clone...); err != nil {
+		return "", err
+	}
+	if err := c.git(env, dir, "checkout", "--detach", rev); err != nil {
+		return "", err
+	}
+	if err := c.git(env, dir, "submodule", "update", "--init", "--recursive"); err != nil {
+		return "", err
+	}
+	head, err := c.output(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(head, rev) {
+		return "", fmt.Errorf("checked out %s; want %s", head, rev)
+	}
+	return c.output(dir, "rev-parse", "HEAD^{tree}")
+}
+
+func (c Cloner) git(env []string, dir string, args ...string) error {
+	c.Log("Running git %s", strings.Join(args, " "))
+	cmd := c.command(env, dir, args...)
+	cmd.Stdout = c.Stderr
+	cmd.Stderr = c.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s: %w", subcommand(args), err)
+	}
+	return nil
+}
+
+// subcommand returns the git subcommand in args, skipping any -c options.
+func subcommand(args []string) string {
+	for len(args) > 2 && args[0] == "-c" {
+		args = args[2:]
+	}
+	return args[0]
+}
+
+func (c Cloner) output(dir string, args ...string) (string, error) {
+	cmd := c.command(nil, dir, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, stderr.String())
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (c Cloner) command(env []string, dir string, args ...string) *exec.Cmd {
+	ctx := c.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Never prompt for credentials; fail instead.
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), env...)
+	return cmd
+}
+
+func isLocal(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err != nil || u.Scheme == "" || u.Scheme == "file"
+}
+
+// tokenEnv returns environment variables which configure git to send the
+// token when cloning rawURL. Using the environment rather than arguments
+// keeps the token out of logs and process listings.
+func (c Cloner) tokenEnv(rawURL string) []string {
+	u, err := url.Parse(rawURL)
+	if err != nil || c.Token.Value == "" || u.Scheme != "https" {
+		return nil
+	}
+	// Send the token to this host only, so submodules hosted elsewhere
+	// never see it.
+	auth := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + c.Token.Value))
+	return gitConfigEnv(os.Environ(), fmt.Sprintf("http.https://%s/.extraheader", u.Host), "Authorization: Basic "+auth)
+}
+
+// gitConfigEnv returns environment variables which add key = value to any
+// configuration already passed to git in environ's GIT_CONFIG_COUNT,
+// GIT_CONFIG_KEY_n and GIT_CONFIG_VALUE_n variables.
+func gitConfigEnv(environ []string, key, value string) []string {
+	n := 0
+	for _, e := range environ {
+		if count, ok := strings.CutPrefix(e, "GIT_CONFIG_COUNT="); ok {
+			// An invalid count is replaced, rather than passed on for git to refuse.
+			n, _ = strconv.Atoi(count)
+		}
+	}
+	return []string{
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", n+1),
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", n, key),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", n, value),
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/actions-go-build/internal/fetch"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// DefaultGOPROXY is used when GOPROXY names no usable proxy.
+const DefaultGOPROXY = "https://proxy.golang.org"
+
+// ModuleProxy downloads module zips from a Go module proxy, as described
+// at https://go.dev/ref/mod#goproxy-protocol.
+type ModuleProxy struct {
+	// URL is the proxy's base URL. It may be a file:// URL.
+	URL string
+}
+
+// ProxyFromGOPROXY returns a ModuleProxy for the first proxy in goproxy, a
+// value in the format of the GOPROXY environment variable. "direct" is
+// skipped, since it doesn't name a proxy. Reaching "off" before any proxy is
+// an error, since it means downloading modules is disallowed.
+func ProxyFromGOPROXY(goproxy string) (ModuleProxy, error) {
+	for _, p := range strings.FieldsFunc(goproxy, func(r rune) bool { return r == ',' || r == '|' }) {
+		switch p = strings.TrimSpace(p); p {
+		case "off":
+			return ModuleProxy{}, fmt.Errorf("module downloads are disabled by GOPROXY=%s", goproxy)
+		case "direct", "":
+			continue
+		}
+		return ModuleProxy{URL: p}, nil
+	}
+	return ModuleProxy{URL: DefaultGOPROXY}, nil
+}
+
+// ZipURL returns the URL of the zip of m.
+func (p ModuleProxy) ZipURL(m module.Version) (string, error) {
+	path, err := module.EscapePath(m.Path)
+	if err != nil {
+		return "", err
+	}
+	version, err := module.EscapeVersion(m.Version)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s/@v/%s.zip", strings.TrimSuffix(p.URL, "/"), path, version), nil
+}
+
+// Download downloads the zip of m to the file at path, using client unless
+// the proxy is a file:// URL.
+func (p ModuleProxy) Download(ctx context.Context, client *fetch.Client, m module.Version, path string) error {
+	zipURL, err := p.ZipURL(m)
+	if err != nil {
+		return err
+	}
+	if u, err := url.Parse(zipURL); err == nil && u.Scheme == "file" {
+		return copyFile(u.Path, path)
+	}
+	return client.Download(ctx, zipURL, fetch.Token{}, path)
+}
+
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dest, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dest, src); err != nil {
+		dest.Close()
+		return err
+	}
+	return dest.Close()
+}
+
+// CheckZipSum returns an error unless the module zip at zipFile has the
+// go.sum-style hash want, e.g. "h1:abc...=".
+func CheckZipSum(zipFile, want string) error {
+	got, err := dirhash.HashZip(zipFile, dirhash.Hash1)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("module zip has hash %s; want %s", got, want)
+	}
+	return nil
+}
+
+// ParseModuleSum returns the expected hash of the zip of m. The spec is
+// either a hash like "h1:abc...=", or the path to a go.sum file which has
+// a line for m.
+func ParseModuleSum(spec string, m module.Version) (string, error) {
+	if strings.HasPrefix(spec, "h1:") {
+		return spec, nil
+	}
+	f, err := os.Open(spec)
+	if err != nil {
+		return "", fmt.Errorf("module sum must be an h1: hash or a go.sum file: %w", err)
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 3 && fields[0] == m.Path && fields[1] == m.Version {
+			return fields[2], nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no hash for %s in %s", m, spec)
+}
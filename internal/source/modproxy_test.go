@@ -0,0 +1,46 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import (
+	"testing"
+
+	"golang.org/x/mod/module"
+)
+
+func TestModuleProxy_ZipURL(t *testing.T) {
+	m := module.Version{Path: "github.com/BurntSushi/toml", Version: "v1.2.3"}
+	cases := []struct {
+		goproxy, want string
+	}{
+		{"", "https://proxy.golang.org/github.com/!burnt!sushi/toml/@v/v1.2.3.zip"},
+		{"direct", "https://proxy.golang.org/github.com/!burnt!sushi/toml/@v/v1.2.3.zip"},
+		{"direct,https://goproxy.example/,off", "https://goproxy.example/github.com/!burnt!sushi/toml/@v/v1.2.3.zip"},
+		{"file:///srv/proxy|https://goproxy.example", "file:///srv/proxy/github.com/!burnt!sushi/toml/@v/v1.2.3.zip"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.goproxy, func(t *testing.T) {
+			p, err := ProxyFromGOPROXY(c.goproxy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.ZipURL(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("got %q; want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestProxyFromGOPROXY_off(t *testing.T) {
+	for _, goproxy := range []string{"off", "direct,off", "off,https://goproxy.example"} {
+		if _, err := ProxyFromGOPROXY(goproxy); err == nil {
+			t.Errorf("got nil error for GOPROXY=%s", goproxy)
+		}
+	}
+}
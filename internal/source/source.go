@@ -0,0 +1,258 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+// Package source knows where to download archives of a repository's source
+// code from, and how those archives are laid out.
+package source
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Repo identifies a repository, as found in crt.Product.Repository.
+type Repo struct {
+	// Host is the forge's host name, e.g. github.com.
+	Host string
+	// Path is the repository path on Host, e.g. hashicorp/lockbox.
+	// On some forges, like GitLab, this can have more than two segments.
+	Path string
+}
+
+// ParseRepo parses a repository of the form host/owner/name.
+func ParseRepo(repository string) (Repo, error) {
+	parts := strings.SplitN(strings.Trim(repository, "/"), "/", 2)
+	if len(parts) != 2 || !strings.Contains(parts[1], "/") {
+		return Repo{}, fmt.Errorf("repository %q not supported, must be in the format %q", repository, "<host>/<owner>/<repo>")
+	}
+	return Repo{Host: parts[0], Path: parts[1]}, nil
+}
+
+// Name is the last segment of Path.
+func (r Repo) Name() string { return path.Base(r.Path) }
+
+// Owner is everything in Path before Name.
+func (r Repo) Owner() string { return path.Dir(r.Path) }
+
+func (r Repo) String() string { return r.Host + "/" + r.Path }
+
+// Provider describes a forge's source archives.
+type Provider interface {
+	// ArchiveURL returns the URL of a zip archive of repo at revision rev.
+	ArchiveURL(repo Repo, rev string) (string, error)
+	// InnerDir returns the name of the directory inside the archive which
+	// contains the source code. An empty string means the archive's only
+	// top-level directory, whatever its name; see FindInnerDir.
+	InnerDir(repo Repo, rev string) (string, error)
+}
+
+// GitHub provides archives from GitHub or GitHub Enterprise.
+type GitHub struct {
+	// BaseURL defaults to https://<host>.
+	BaseURL string
+	// APIURL is the base URL of the REST API. It defaults to
+	// https://api.github.com for github.com, and <BaseURL>/api/v3 otherwise.
+	APIURL string
+}
+
+func (p GitHub) ArchiveURL(r Repo, rev string) (string, error) {
+	return fmt.Sprintf("%s/%s/archive/%s.zip", baseURL(p.BaseURL, r), r.Path, rev), nil
+}
+
+// APIArchiveURL returns the URL of a zip archive of repo at revision rev from
+// the REST API. Unlike ArchiveURL, it accepts a token, so it must be used for
+// private repositories. Its archive's only top-level directory is named
+// <owner>-<name>-<short commit hash>, so its inner directory must be found
+// with FindInnerDir.
+func (p GitHub) APIArchiveURL(r Repo, rev string) string {
+	api := strings.TrimSuffix(p.APIURL, "/")
+	if api == "" {
+		if p.BaseURL == "" && r.Host == "github.com" {
+			api = "https://api.github.com"
+		} else {
+			api = baseURL(p.BaseURL, r) + "/api/v3"
+		}
+	}
+	return fmt.Sprintf("%s/repos/%s/zipball/%s", api, r.Path, rev)
+}
+
+func (p GitHub) InnerDir(r Repo, rev string) (string, error) {
+	return fmt.Sprintf("%s-%s", r.Name(), rev), nil
+}
+
+// GitLab provides archives from GitLab.
+type GitLab struct {
+	// BaseURL defaults to https://<host>.
+	BaseURL string
+}
+
+func (p GitLab) ArchiveURL(r Repo, rev string) (string, error) {
+	return fmt.Sprintf("%s/%s/-/archive/%s/%s-%s.zip", baseURL(p.BaseURL, r), r.Path, rev, r.Name(), rev), nil
+}
+
+func (p GitLab) InnerDir(r Repo, rev string) (string, error) {
+	return fmt.Sprintf("%s-%s", r.Name(), rev), nil
+}
+
+// Gitea provides archives from Gitea or Forgejo.
+type Gitea struct {
+	// BaseURL defaults to https://<host>.
+	BaseURL string
+}
+
+func (p Gitea) ArchiveURL(r Repo, rev string) (string, error) {
+	return fmt.Sprintf("%s/%s/archive/%s.zip", baseURL(p.BaseURL, r), r.Path, rev), nil
+}
+
+func (p Gitea) InnerDir(r Repo, rev string) (string, error) {
+	return r.Name(), nil
+}
+
+func baseURL(u string, r Repo) string {
+	if u == "" {
+		return "https://" + r.Host
+	}
+	return strings.TrimSuffix(u, "/")
+}
+
+// Template provides archives from anywhere, using text/template strings.
+// The templates are executed with TemplateData.
+type Template struct {
+	// URLTemplate is the archive URL template,
+	// e.g. https://mirror.example.com/{{.Path}}/{{.Revision}}.zip
+	URLTemplate string
+	// InnerDirTemplate is the inner directory template,
+	// e.g. {{.Name}}-{{.Revision}}.
+	// If empty, the archive's only top-level directory is used.
+	InnerDirTemplate string
+}
+
+// TemplateData is the data available to Template's templates.
+type TemplateData struct {
+	Host, Path, Owner, Name, Revision string
+}
+
+func (p Template) ArchiveURL(r Repo, rev string) (string, error) {
+	return execute("URL", p.URLTemplate, r, rev)
+}
+
+func (p Template) InnerDir(r Repo, rev string) (string, error) {
+	if p.InnerDirTemplate == "" {
+		return "", nil
+	}
+	return execute("inner directory", p.InnerDirTemplate, r, rev)
+}
+
+func execute(what, text string, r Repo, rev string) (string, error) {
+	t, err := template.New(what).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing %s template: %w", what, err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, TemplateData{
+		Host:     r.Host,
+		Path:     r.Path,
+		Owner:    r.Owner(),
+		Name:     r.Name(),
+		Revision: rev,
+	}); err != nil {
+		return "", fmt.Errorf("executing %s template: %w", what, err)
+	}
+	return b.String(), nil
+}
+
+// Providers maps host names to providers.
+type Providers map[string]Provider
+
+// DefaultProviders returns the providers for well-known public forges.
+func DefaultProviders() Providers {
+	return Providers{
+		"github.com":   GitHub{},
+		"gitlab.com":   GitLab{},
+		"gitea.com":    Gitea{},
+		"codeberg.org": Gitea{},
+	}
+}
+
+// ParseProvider parses a provider specification of the form HOST=KIND, where
+// KIND is one of github, gitlab, or gitea, or HOST=URL_TEMPLATE, where
+// URL_TEMPLATE is a Template.URLTemplate. It adds the parsed provider to p.
+func (p Providers) ParseProvider(spec string) error {
+	host, kind, ok := strings.Cut(spec, "=")
+	if !ok || host == "" || kind == "" {
+		return fmt.Errorf("invalid source provider %q, must be in the format HOST=KIND or HOST=URL_TEMPLATE", spec)
+	}
+	switch kind {
+	case "github":
+		p[host] = GitHub{}
+	case "gitlab":
+		p[host] = GitLab{}
+	case "gitea":
+		p[host] = Gitea{}
+	default:
+		if !strings.Contains(kind, "://") {
+			return fmt.Errorf("unknown source provider kind %q; want github, gitlab, gitea, or a URL template", kind)
+		}
+		if _, err := template.New("").Parse(kind); err != nil {
+			return fmt.Errorf("invalid URL template for %s: %w", host, err)
+		}
+		p[host] = Template{URLTemplate: kind}
+	}
+	return nil
+}
+
+// For returns the provider for repository, along with the parsed Repo.
+func (p Providers) For(repository string) (Provider, Repo, error) {
+	r, err := ParseRepo(repository)
+	if err != nil {
+		return nil, r, err
+	}
+	provider, ok := p[r.Host]
+	if !ok {
+		var hosts []string
+		for h := range p {
+			hosts = append(hosts, h)
+		}
+		sort.Strings(hosts)
+		return nil, r, fmt.Errorf("no source provider for host %q (known hosts: %s)", r.Host, strings.Join(hosts, ", "))
+	}
+	return provider, r, nil
+}
+
+// IsGitHub returns true if host is configured to use the GitHub provider,
+// e.g. for GitHub Enterprise, or isn't configured at all but is one of
+// GitHub's own hosts. GitHub tokens must only ever be sent to these hosts.
+// A host configured with any other provider, e.g. github.com with a mirror's
+// URL template, isn't GitHub.
+func (p Providers) IsGitHub(host string) bool {
+	if provider, ok := p[host]; ok {
+		_, isGitHub := provider.(GitHub)
+		return isGitHub
+	}
+	return host == "github.com" ||
+		strings.HasSuffix(host, ".github.com") ||
+		strings.HasSuffix(host, ".githubusercontent.com")
+}
+
+// FindInnerDir returns the name of the only directory in dir.
+func FindInnerDir(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, e.Name())
+		}
+	}
+	if len(dirs) != 1 {
+		return "", fmt.Errorf("want exactly one top-level directory in %s; found %d", filepath.Base(dir), len(dirs))
+	}
+	return dirs[0], nil
+}
@@ -0,0 +1,150 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package source
+
+import "testing"
+
+func TestProviders_For_ok(t *testing.T) {
+	providers := DefaultProviders()
+	for _, spec := range []string{
+		"git.example.com=gitea",
+		"mirror.example.com=https://mirror.example.com/{{.Owner}}/{{.Name}}/{{.Revision}}.zip",
+	} {
+		if err := providers.ParseProvider(spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cases := []struct {
+		repository, wantURL, wantInnerDir string
+	}{
+		{
+			"github.com/hashicorp/lockbox",
+			"https://github.com/hashicorp/lockbox/archive/cabba9e.zip",
+			"lockbox-cabba9e",
+		},
+		{
+			"gitlab.com/hashicorp/tools/lockbox",
+			"https://gitlab.com/hashicorp/tools/lockbox/-/archive/cabba9e/lockbox-cabba9e.zip",
+			"lockbox-cabba9e",
+		},
+		{
+			"git.example.com/hashicorp/lockbox",
+			"https://git.example.com/hashicorp/lockbox/archive/cabba9e.zip",
+			"lockbox",
+		},
+		{
+			"mirror.example.com/hashicorp/lockbox",
+			"https://mirror.example.com/hashicorp/lockbox/cabba9e.zip",
+			"",
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.repository, func(t *testing.T) {
+			p, repo, err := providers.For(c.repository)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotURL, err := p.ArchiveURL(repo, "cabba9e")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotURL != c.wantURL {
+				t.Errorf("got URL %q; want %q", gotURL, c.wantURL)
+			}
+			gotInnerDir, err := p.InnerDir(repo, "cabba9e")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotInnerDir != c.wantInnerDir {
+				t.Errorf("got inner dir %q; want %q", gotInnerDir, c.wantInnerDir)
+			}
+		})
+	}
+}
+
+func TestGitHub_APIArchiveURL(t *testing.T) {
+	cases := []struct {
+		desc     string
+		provider GitHub
+		repo     Repo
+		want     string
+	}{
+		{
+			"github.com",
+			GitHub{},
+			Repo{"github.com", "hashicorp/lockbox"},
+			"https://api.github.com/repos/hashicorp/lockbox/zipball/cabba9e",
+		},
+		{
+			"enterprise",
+			GitHub{},
+			Repo{"github.example", "hashicorp/lockbox"},
+			"https://github.example/api/v3/repos/hashicorp/lockbox/zipball/cabba9e",
+		},
+		{
+			"enterprise base url",
+			GitHub{BaseURL: "https://git.example/"},
+			Repo{"github.example", "hashicorp/lockbox"},
+			"https://git.example/api/v3/repos/hashicorp/lockbox/zipball/cabba9e",
+		},
+		{
+			"api url",
+			GitHub{BaseURL: "https://git.example", APIURL: "https://api.git.example/"},
+			Repo{"github.example", "hashicorp/lockbox"},
+			"https://api.git.example/repos/hashicorp/lockbox/zipball/cabba9e",
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			if got := c.provider.APIArchiveURL(c.repo, "cabba9e"); got != c.want {
+				t.Errorf("got %q; want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestProviders_ParseProvider_err(t *testing.T) {
+	cases := map[string]string{
+		"github.com":            `invalid source provider "github.com", must be in the format HOST=KIND or HOST=URL_TEMPLATE`,
+		"example.com=bitbucket": `unknown source provider kind "bitbucket"; want github, gitlab, gitea, or a URL template`,
+		"example.com=https://example.com/{{.Path": "invalid URL template for example.com: template: :1: unclosed action",
+	}
+	for spec, want := range cases {
+		spec, want := spec, want
+		t.Run(spec, func(t *testing.T) {
+			err := Providers{}.ParseProvider(spec)
+			if err == nil || err.Error() != want {
+				t.Fatalf("got error %v; want %q", err, want)
+			}
+		})
+	}
+}
+
+func TestProviders_IsGitHub(t *testing.T) {
+	providers := DefaultProviders()
+	for _, spec := range []string{
+		"ghe.example.com=github",
+		"github.com=https://mirror.example.com/{{.Path}}/{{.Revision}}.zip",
+	} {
+		if err := providers.ParseProvider(spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for host, want := range map[string]bool{
+		"ghe.example.com":                   true,
+		"codeload.github.com":               true,
+		"objects.githubusercontent.com":     true,
+		"github.com":                        false,
+		"mirror.example.com":                false,
+		"gitlab.com":                        false,
+		"github.com.evil.example":           false,
+		"raw.githubusercontent.com.example": false,
+	} {
+		if got := providers.IsGitHub(host); got != want {
+			t.Errorf("IsGitHub(%q) = %t; want %t", host, got, want)
+		}
+	}
+}
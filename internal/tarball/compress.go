@@ -0,0 +1,68 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package tarball
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ulikunitz/xz"
+)
+
+// Compression is the compression applied to a tarball.
+type Compression string
+
+const (
+	Gzip Compression = "gzip"
+	XZ   Compression = "xz"
+)
+
+// Compress wraps w in a compressor with fixed settings and header fields,
+// so the compressed bytes depend only on the uncompressed bytes.
+func Compress(w io.Writer, c Compression) (io.WriteCloser, error) {
+	switch c {
+	case Gzip:
+		gz, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+		if err != nil {
+			return nil, err
+		}
+		// Never record a file name or mtime, and always claim an unknown OS.
+		gz.Header = gzip.Header{OS: 255}
+		return gz, nil
+	case XZ:
+		return xz.WriterConfig{CheckSum: xz.CRC64}.NewWriter(w)
+	}
+	return nil, fmt.Errorf("unknown compression %q", c)
+}
+
+// ToFile is a convenience function which writes a compressed tarball of
+// dir to file. It is the tarball equivalent of zipper.ZipToFile.
+func ToFile(dir, file string, c Compression, modTime time.Time, logFunc func(string, ...any), opts ...Option) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cw, err := Compress(f, c)
+	if err != nil {
+		return err
+	}
+	logFunc("Creating %s tarball of %q", c, dir)
+	tw := New(cw, modTime, logFunc, opts...)
+	if err := tw.AddDir(dir, ""); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := cw.Close(); err != nil {
+		return err
+	}
+	logFunc("Finished creating tarball of %q", dir)
+	return f.Close()
+}
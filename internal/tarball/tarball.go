@@ -0,0 +1,151 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package tarball
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/internal/filetree"
+)
+
+const (
+	// Owner is the user and group name written to every entry.
+	Owner = "root"
+	// OwnerID is the uid and gid written to every entry.
+	OwnerID = 0
+
+	fileMode = 0o644
+	execMode = 0o755
+	dirMode  = 0o755
+)
+
+// Writer writes reproducible tar streams. Every header is normalized so that
+// the bytes written depend only on entry names, contents, the executable bit,
+// and the modification time passed to New; never on the host filesystem's
+// ownership, permissions, or timestamps.
+type Writer struct {
+	tw            *tar.Writer
+	modTime       time.Time
+	written       map[string]struct{}
+	log           func(string, ...any)
+	preservePaths bool
+}
+
+// Option configures a Writer.
+type Option func(*Writer)
+
+// WithPreservePaths makes AddDir keep each file's path relative to the
+// directory, rather than flattening the hierarchy. It has the same
+// semantics as zipper.WithPreservePaths.
+func WithPreservePaths(on bool) Option { return func(w *Writer) { w.preservePaths = on } }
+
+// New returns a new Writer which stamps every entry with modTime.
+func New(w io.Writer, modTime time.Time, logFunc func(string, ...any), opts ...Option) *Writer {
+	tw := &Writer{
+		tw:      tar.NewWriter(w),
+		modTime: modTime.UTC().Truncate(time.Second),
+		written: map[string]struct{}{},
+		log:     logFunc,
+	}
+	for _, o := range opts {
+		o(tw)
+	}
+	return tw
+}
+
+// AddDir adds the contents of dir to the archive, placing them under
+// prefix. Entries are written in lexical order. Like zipper.ZipDir, the
+// directory hierarchy is flattened unless WithPreservePaths is used, and
+// filename conflicts result in error.
+func (w *Writer) AddDir(dir, prefix string) error {
+	return filetree.Walk(dir, w.preservePaths, func(e filetree.Entry) error {
+		name := path.Join(prefix, e.Name)
+		switch e.Type {
+		case filetree.Dir:
+			return w.WriteDir(name)
+		case filetree.Symlink:
+			return w.WriteSymlink(name, e.LinkTarget)
+		}
+		return w.AddFile(name, e.Path)
+	})
+}
+
+// AddFile adds the file at source to the archive with the given name.
+func (w *Writer) AddFile(name, source string) error {
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%q is not a regular file", source)
+	}
+	w.log("Adding %q to tarball, from %q", name, source)
+	if err := w.WriteFile(name, info.Mode()&0o111 != 0, info.Size(), f); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// WriteFile writes a single file entry of the given size, reading its contents from r.
+func (w *Writer) WriteFile(name string, executable bool, size int64, r io.Reader) error {
+	mode := int64(fileMode)
+	if executable {
+		mode = execMode
+	}
+	if err := w.writeHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     mode,
+		Size:     size,
+	}); err != nil {
+		return err
+	}
+	_, err := io.CopyN(w.tw, r, size)
+	return err
+}
+
+// WriteDir writes a single directory entry.
+func (w *Writer) WriteDir(name string) error {
+	return w.writeHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     path.Clean(name) + "/",
+		Mode:     dirMode,
+	})
+}
+
+// WriteSymlink writes a single symlink entry.
+func (w *Writer) WriteSymlink(name, target string) error {
+	return w.writeHeader(&tar.Header{
+		Typeflag: tar.TypeSymlink,
+		Name:     name,
+		Linkname: target,
+		Mode:     0o777,
+	})
+}
+
+func (w *Writer) writeHeader(h *tar.Header) error {
+	if _, ok := w.written[h.Name]; ok {
+		return fmt.Errorf("duplicate entry %q", h.Name)
+	}
+	w.written[h.Name] = struct{}{}
+	h.ModTime = w.modTime
+	h.Uid, h.Gid = OwnerID, OwnerID
+	h.Uname, h.Gname = Owner, Owner
+	return w.tw.WriteHeader(h)
+}
+
+// Close writes the tar footer. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	return w.tw.Close()
+}
@@ -0,0 +1,120 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package tarball
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/pkg/digest"
+)
+
+var modTime = time.Date(2022, 7, 4, 11, 33, 33, 0, time.UTC)
+
+func TestToFile_deterministic(t *testing.T) {
+	for _, c := range []Compression{Gzip, XZ} {
+		c := c
+		t.Run(string(c), func(t *testing.T) {
+			var sums []string
+			for i, mode := range []os.FileMode{0o600, 0o644, 0o664} {
+				dir := t.TempDir()
+				writeFile(t, filepath.Join(dir, "lockbox"), "binary", 0o755)
+				writeFile(t, filepath.Join(dir, "docs", "README"), "readme", mode)
+				mtime := time.Now().Add(time.Duration(i) * time.Hour)
+				if err := os.Chtimes(filepath.Join(dir, "lockbox"), mtime, mtime); err != nil {
+					t.Fatal(err)
+				}
+				out := filepath.Join(t.TempDir(), "out.tar")
+				if err := ToFile(dir, out, c, modTime, t.Logf); err != nil {
+					t.Fatal(err)
+				}
+				sum, err := digest.FileSHA256Hex(out)
+				if err != nil {
+					t.Fatal(err)
+				}
+				sums = append(sums, sum)
+			}
+			for _, s := range sums[1:] {
+				if s != sums[0] {
+					t.Fatalf("got tarball digests %v; want all identical", sums)
+				}
+			}
+		})
+	}
+}
+
+func TestWriter_headers(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "lockbox"), "binary", 0o700)
+	writeFile(t, filepath.Join(dir, "sub", "LICENSE"), "license", 0o600)
+
+	buf := &bytes.Buffer{}
+	gz, err := Compress(buf, Gzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := New(gz, modTime, t.Logf)
+	if err := w.AddDir(dir, ""); err != nil {
+		t.Fatal(err)
+	}
+	must(t, w.Close())
+	must(t, gz.Close())
+
+	gr, err := gzip.NewReader(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gr.ModTime.IsZero() || gr.Name != "" {
+		t.Errorf("got gzip header mtime %s name %q; want both empty", gr.ModTime, gr.Name)
+	}
+
+	want := map[string]int64{"lockbox": 0o755, "LICENSE": 0o644}
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		must(t, err)
+		wantMode, ok := want[h.Name]
+		if !ok {
+			t.Errorf("unexpected entry %q", h.Name)
+			continue
+		}
+		delete(want, h.Name)
+		if h.Mode != wantMode {
+			t.Errorf("%s: got mode %o; want %o", h.Name, h.Mode, wantMode)
+		}
+		if !h.ModTime.Equal(modTime) {
+			t.Errorf("%s: got mtime %s; want %s", h.Name, h.ModTime, modTime)
+		}
+		if h.Uid != OwnerID || h.Gid != OwnerID || h.Uname != Owner || h.Gname != Owner {
+			t.Errorf("%s: got owner %d:%d (%s:%s); want normalized owner", h.Name, h.Uid, h.Gid, h.Uname, h.Gname)
+		}
+	}
+	for name := range want {
+		t.Errorf("entry %q missing from tarball", name)
+	}
+}
+
+func writeFile(t *testing.T, path, contents string, mode os.FileMode) {
+	t.Helper()
+	must(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	must(t, os.WriteFile(path, []byte(contents), mode))
+	// Chmod explicitly, so the umask doesn't interfere.
+	must(t, os.Chmod(path, mode))
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
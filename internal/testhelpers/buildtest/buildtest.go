@@ -0,0 +1,42 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+// Package buildtest provides build configs and results for the tests of
+// packages which read or summarise them.
+package buildtest
+
+import (
+	"testing"
+
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	"github.com/hashicorp/actions-go-build/pkg/crt"
+)
+
+// Config returns the config for a clean build of lockbox 1.2.3 for
+// linux/amd64. Tests set whatever else they depend on.
+func Config() build.Config {
+	var c build.Config
+	c.Product = crt.Product{
+		Name:       "lockbox",
+		Version:    crt.ProductVersion{Full: "1.2.3"},
+		Revision:   "cabba9e",
+		SourceHash: "cabba9e",
+	}
+	c.Parameters.OS = "linux"
+	c.Parameters.Arch = "amd64"
+	return c
+}
+
+// Result returns a successful build result for Config.
+func Result() build.Result {
+	return build.Result{Config: Config(), Successful: true}
+}
+
+// WriteDocument writes v to the file at path using build.WriteDocumentFile,
+// failing the test if it can't.
+func WriteDocument(t testing.TB, path string, v any) {
+	t.Helper()
+	if err := build.WriteDocumentFile(path, v); err != nil {
+		t.Fatal(err)
+	}
+}
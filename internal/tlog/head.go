@@ -0,0 +1,82 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package tlog
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TreeHead is a signed statement of the size and root hash of the log at a
+// point in time. Once a tree head has been published, the log can't be
+// changed without contradicting it.
+type TreeHead struct {
+	Size      int
+	RootHash  Hash
+	Timestamp time.Time
+	Signature []byte
+}
+
+// message returns what's signed: everything but the signature.
+func (h TreeHead) message() []byte {
+	return fmt.Appendf(nil, "actions-go-build tree head v1\n%d\n%s\n%s\n",
+		h.Size, h.RootHash, h.Timestamp.UTC().Format(time.RFC3339Nano))
+}
+
+func (h *TreeHead) sign(key ed25519.PrivateKey) {
+	h.Signature = ed25519.Sign(key, h.message())
+}
+
+// ErrBadSignature is returned when a tree head's signature can't be verified.
+var ErrBadSignature = errors.New("tree head signature is invalid")
+
+// Verify checks that h was signed by the owner of pub.
+func (h TreeHead) Verify(pub ed25519.PublicKey) error {
+	if !ed25519.Verify(pub, h.message(), h.Signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// InclusionProof proves that a verification result is in the log.
+type InclusionProof struct {
+	LeafIndex int
+	LeafHash  Hash
+	Hashes    []Hash
+	TreeHead  TreeHead
+}
+
+// Verify checks that p proves entry, a verification result document, is in
+// the log whose tree heads are signed by the owner of pub.
+func (p InclusionProof) Verify(pub ed25519.PublicKey, entry []byte) error {
+	if err := p.TreeHead.Verify(pub); err != nil {
+		return err
+	}
+	leaf := LeafHash(entry)
+	if leaf != p.LeafHash {
+		return fmt.Errorf("%w: the proof is for a different verification result", ErrInvalidProof)
+	}
+	return VerifyInclusion(p.LeafIndex, p.TreeHead.Size, leaf, p.Hashes, p.TreeHead.RootHash)
+}
+
+// ConsistencyProof proves that the log at Old is a prefix of the log at New,
+// i.e. that nothing was changed or removed in between.
+type ConsistencyProof struct {
+	Old    TreeHead
+	New    TreeHead
+	Hashes []Hash
+}
+
+// Verify checks that p proves the log was only appended to between its tree
+// heads, which were both signed by the owner of pub.
+func (p ConsistencyProof) Verify(pub ed25519.PublicKey) error {
+	for _, h := range []TreeHead{p.Old, p.New} {
+		if err := h.Verify(pub); err != nil {
+			return err
+		}
+	}
+	return VerifyConsistency(p.Old.Size, p.New.Size, p.Old.RootHash, p.New.RootHash, p.Hashes)
+}
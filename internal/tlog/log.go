@@ -0,0 +1,251 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+// Package tlog is a local, append-only transparency log of verification
+// results. Each result, exactly as stored in the log, is a leaf of a Merkle
+// tree, and every append
+// produces a signed tree head, so that inclusion and consistency proofs can be
+// checked offline by anyone with the log's public key.
+package tlog
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/internal/atomicfile"
+	"github.com/hashicorp/actions-go-build/internal/signing"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	"github.com/hashicorp/composite-action-framework-go/pkg/json"
+)
+
+// Files and directories within the log's directory.
+const (
+	// PublicKeyFile is the key tree heads are verified with.
+	PublicKeyFile  = "key.pub"
+	privateKeyFile = "key.pem"
+	// leavesFile lists the hash of each leaf, one per line, in order.
+	leavesFile = "leaves"
+	// entriesDir holds each verification result, named by its leaf index.
+	entriesDir = "entries"
+	// headsDir holds each signed tree head, named by its size.
+	headsDir = "heads"
+)
+
+// Log is a transparency log kept in a directory. It isn't safe to use the
+// same directory from more than one process at a time.
+type Log struct {
+	dir    string
+	key    ed25519.PrivateKey
+	leaves []Hash
+	now    func() time.Time
+}
+
+// Open opens the log in dir, creating it along with a new signing key if it
+// doesn't exist. Opening a log whose leaves don't match its latest tree head
+// is an error.
+func Open(dir string) (*Log, error) {
+	l := &Log{dir: dir, now: time.Now}
+	for _, d := range []string{entriesDir, headsDir} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	l.key, err = signing.ReadPrivateKeyFile(l.path(privateKeyFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		if _, l.key, err = ed25519.GenerateKey(rand.Reader); err == nil {
+			err = signing.WriteKeyFiles(l.key, l.path(privateKeyFile), l.path(PublicKeyFile))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if l.leaves, err = l.readLeaves(); err != nil {
+		return nil, err
+	}
+	head, err := l.Head()
+	if errors.Is(err, fs.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if head.Size > len(l.leaves) || rootHash(l.leaves[:head.Size]) != head.RootHash {
+		return nil, fmt.Errorf("log in %s doesn't match its tree head of size %d", dir, head.Size)
+	}
+	return l, nil
+}
+
+func (l *Log) path(elem ...string) string {
+	return filepath.Join(append([]string{l.dir}, elem...)...)
+}
+
+// PublicKey returns the key the log's tree heads can be verified with.
+func (l *Log) PublicKey() ed25519.PublicKey { return l.key.Public().(ed25519.PublicKey) }
+
+// Size returns the number of leaves in the log.
+func (l *Log) Size() int { return len(l.leaves) }
+
+func (l *Log) readLeaves() ([]Hash, error) {
+	f, err := os.Open(l.path(leavesFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var leaves []Hash
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var h Hash
+		if err := h.UnmarshalText(s.Bytes()); err != nil {
+			return nil, fmt.Errorf("reading leaf %d: %w", len(leaves), err)
+		}
+		leaves = append(leaves, h)
+	}
+	return leaves, s.Err()
+}
+
+// Append adds each result to the log, then signs and returns the new tree
+// head. index is the leaf index of the first result. Results are stored as
+// written by build.MarshalDocument, which is how verify writes them too.
+func (l *Log) Append(results ...build.VerificationResult) (index int, head TreeHead, err error) {
+	entries := make([][]byte, len(results))
+	for i, r := range results {
+		if entries[i], err = build.MarshalDocument(r); err != nil {
+			return len(l.leaves), head, err
+		}
+	}
+	return l.AppendEntries(entries...)
+}
+
+// AppendEntries is like Append, but for verification result documents that
+// are already encoded, and may be signed. Each entry is stored and hashed
+// exactly as given, so that the file it was read from can be proven to be in
+// the log. Signatures aren't checked; that's up to the caller.
+func (l *Log) AppendEntries(entries ...[]byte) (index int, head TreeHead, err error) {
+	index = len(l.leaves)
+	leaves := make([]Hash, len(entries))
+	for i, data := range entries {
+		doc, err := signing.Trust{}.Open(fmt.Sprintf("entry %d", i), data)
+		if err != nil {
+			return index, head, err
+		}
+		if _, err := build.ReadDocument[build.VerificationResult](doc); err != nil {
+			return index, head, fmt.Errorf("entry %d isn't a verification result: %w", i, err)
+		}
+		leaves[i] = LeafHash(data)
+		// Entries are written before their leaves, so that every leaf has an
+		// entry even if appending is interrupted.
+		if err := os.WriteFile(l.entryPath(index+i), data, 0o644); err != nil {
+			return index, head, err
+		}
+	}
+	f, err := os.OpenFile(l.path(leavesFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return index, head, err
+	}
+	defer f.Close()
+	for _, h := range leaves {
+		if _, err := fmt.Fprintln(f, h); err != nil {
+			return index, head, err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		return index, head, err
+	}
+	l.leaves = append(l.leaves, leaves...)
+
+	head = TreeHead{Size: len(l.leaves), RootHash: rootHash(l.leaves), Timestamp: l.now().UTC()}
+	head.sign(l.key)
+	return index, head, l.saveHead(head)
+}
+
+func (l *Log) saveHead(head TreeHead) error {
+	return atomicfile.WriteJSON(l.path(headsDir, strconv.Itoa(head.Size)+".json"), head)
+}
+
+// Head returns the latest signed tree head, or an error wrapping
+// fs.ErrNotExist if nothing has been appended.
+func (l *Log) Head() (TreeHead, error) {
+	entries, err := os.ReadDir(l.path(headsDir))
+	if err != nil {
+		return TreeHead{}, err
+	}
+	latest := 0
+	for _, e := range entries {
+		name := e.Name()
+		if n, err := strconv.Atoi(name[:len(name)-len(filepath.Ext(name))]); err == nil && n > latest {
+			latest = n
+		}
+	}
+	return l.TreeHead(latest)
+}
+
+// TreeHead returns the signed tree head from when the log had size leaves.
+func (l *Log) TreeHead(size int) (TreeHead, error) {
+	head, err := json.ReadFile[TreeHead](l.path(headsDir, strconv.Itoa(size)+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return head, fmt.Errorf("no tree head of size %d: %w", size, err)
+	}
+	return head, err
+}
+
+func (l *Log) entryPath(index int) string {
+	return l.path(entriesDir, strconv.Itoa(index)+".json")
+}
+
+// Entry returns the verification result document at index, exactly as it
+// was appended, so that it can be given to prove-inclusion.
+func (l *Log) Entry(index int) ([]byte, error) {
+	return os.ReadFile(l.entryPath(index))
+}
+
+// ProveInclusion returns a proof that entry, a verification result
+// document, is in the log as of the latest tree head. If entry was appended
+// more than once, the proof is for the first.
+func (l *Log) ProveInclusion(entry []byte) (InclusionProof, error) {
+	leaf := LeafHash(entry)
+	head, err := l.Head()
+	if err != nil {
+		return InclusionProof{}, err
+	}
+	for i, h := range l.leaves[:head.Size] {
+		if h == leaf {
+			return InclusionProof{
+				LeafIndex: i,
+				LeafHash:  leaf,
+				Hashes:    inclusionProof(l.leaves[:head.Size], i),
+				TreeHead:  head,
+			}, nil
+		}
+	}
+	return InclusionProof{}, fmt.Errorf("verification result with leaf hash %s isn't in the log", leaf)
+}
+
+// ProveConsistency returns a proof that the log as of the tree head of size
+// is a prefix of the log as of the latest tree head.
+func (l *Log) ProveConsistency(size int) (ConsistencyProof, error) {
+	old, err := l.TreeHead(size)
+	if err != nil {
+		return ConsistencyProof{}, err
+	}
+	head, err := l.Head()
+	if err != nil {
+		return ConsistencyProof{}, err
+	}
+	return ConsistencyProof{
+		Old:    old,
+		New:    head,
+		Hashes: consistencyProof(l.leaves[:head.Size], old.Size),
+	}, nil
+}
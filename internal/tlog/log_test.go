@@ -0,0 +1,189 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package tlog
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/actions-go-build/internal/signing"
+	"github.com/hashicorp/actions-go-build/internal/testhelpers/buildtest"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+// versionResult returns a verification result for version, so results can
+// be told apart.
+func versionResult(version string) build.VerificationResult {
+	r := buildtest.Result()
+	r.Config.Product.Version.Full = version
+	return build.VerificationResult{Primary: &r, Verification: &r, ReproducedCorrectly: true}
+}
+
+// versionEntry returns versionResult(version) encoded as Append stores it.
+func versionEntry(t *testing.T, version string) []byte {
+	t.Helper()
+	data, err := build.MarshalDocument(versionResult(version))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// roundTrip encodes and decodes v, as happens to proofs given to auditors.
+func roundTrip[T any](t *testing.T, v T) T {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out T
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestLog(t *testing.T) {
+	dir := tmp.Dir(t)
+	l, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Head(); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("got %v for an empty log's head; want ErrNotExist", err)
+	}
+	if _, _, err := l.Append(versionResult("1.0.0"), versionResult("1.0.1")); err != nil {
+		t.Fatal(err)
+	}
+	index, head, err := l.Append(versionResult("1.0.2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index != 2 || head.Size != 3 {
+		t.Fatalf("got index %d, size %d; want 2, 3", index, head.Size)
+	}
+
+	// The log, and its key, are the same once reopened.
+	if l, err = Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := signing.ReadPublicKeyFile(filepath.Join(dir, PublicKeyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equal(l.PublicKey()) {
+		t.Fatal("public key changed")
+	}
+	if got, err := l.Entry(1); err != nil || !bytes.Equal(got, versionEntry(t, "1.0.1")) {
+		t.Fatalf("got entry %s, %v", got, err)
+	}
+
+	for _, v := range []string{"1.0.0", "1.0.1", "1.0.2"} {
+		proof, err := l.ProveInclusion(versionEntry(t, v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := roundTrip(t, proof).Verify(pub, versionEntry(t, v)); err != nil {
+			t.Errorf("inclusion of %s: %s", v, err)
+		}
+		if err := proof.Verify(pub, versionEntry(t, "2.0.0")); !errors.Is(err, ErrInvalidProof) {
+			t.Errorf("got %v verifying inclusion of the wrong result; want ErrInvalidProof", err)
+		}
+	}
+	if _, err := l.ProveInclusion(versionEntry(t, "2.0.0")); err == nil {
+		t.Error("got nil error proving inclusion of a result not in the log")
+	}
+
+	proof, err := l.ProveConsistency(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := roundTrip(t, proof).Verify(pub); err != nil {
+		t.Errorf("consistency: %s", err)
+	}
+	proof.New.Size++
+	if err := proof.Verify(pub); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("got %v verifying a changed tree head; want ErrBadSignature", err)
+	}
+}
+
+func TestOpen_modified(t *testing.T) {
+	dir := tmp.Dir(t)
+	l, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := l.Append(versionResult("1.0.0"), versionResult("1.0.1")); err != nil {
+		t.Fatal(err)
+	}
+	leaf := LeafHash(versionEntry(t, "1.0.0"))
+	if err := os.WriteFile(filepath.Join(dir, leavesFile), []byte(leaf.String()+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(dir); err == nil {
+		t.Fatal("got nil error opening a log with a removed leaf")
+	}
+}
+
+func TestLog_AppendEntries(t *testing.T) {
+	dir := tmp.Dir(t)
+	l, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Formatting is kept, so the file that was appended is what's proven.
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, versionEntry(t, "1.0.0")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := l.AppendEntries(compact.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := l.Entry(0); err != nil || !bytes.Equal(got, compact.Bytes()) {
+		t.Fatalf("got entry %s, %v; want %s", got, err, compact.Bytes())
+	}
+	if _, err := l.ProveInclusion(compact.Bytes()); err != nil {
+		t.Error(err)
+	}
+	if _, err := l.ProveInclusion(versionEntry(t, "1.0.0")); err == nil {
+		t.Error("got nil error proving inclusion of a differently formatted entry")
+	}
+
+	// Signed entries are kept with their signatures.
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := json.Marshal(signing.Sign(key, versionEntry(t, "2.0.0")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := l.AppendEntries(signed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.ProveInclusion(signed); err != nil {
+		t.Error(err)
+	}
+
+	if _, _, err := l.AppendEntries([]byte(`{"foo": "bar"}`)); err == nil {
+		t.Error("got nil error appending something other than a verification result")
+	}
+	if l.Size() != 2 {
+		t.Errorf("got size %d; want 2", l.Size())
+	}
+	heads, err := os.ReadDir(filepath.Join(dir, headsDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(heads) != 2 || heads[0].Name() != "1.json" || heads[1].Name() != "2.json" {
+		t.Errorf("got tree head files %v; want 1.json and 2.json", heads)
+	}
+}
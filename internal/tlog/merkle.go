@@ -0,0 +1,180 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package tlog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// Hash is a SHA-256 hash of a leaf or node of the tree, written as hex.
+type Hash [sha256.Size]byte
+
+func (h Hash) String() string { return hex.EncodeToString(h[:]) }
+
+func (h Hash) MarshalText() ([]byte, error) { return []byte(h.String()), nil }
+
+func (h *Hash) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	if len(b) != len(h) {
+		return fmt.Errorf("hash is %d bytes, want %d", len(b), len(h))
+	}
+	copy(h[:], b)
+	return nil
+}
+
+// The tree is an RFC 6962 Merkle tree. Leaves and nodes are hashed with
+// different prefixes so that a leaf can never be passed off as a node.
+
+// LeafHash returns the hash of the leaf containing data.
+func LeafHash(data []byte) Hash {
+	return sha256.Sum256(append([]byte{0}, data...))
+}
+
+func nodeHash(left, right Hash) Hash {
+	b := make([]byte, 0, 1+2*len(left))
+	b = append(b, 1)
+	b = append(b, left[:]...)
+	b = append(b, right[:]...)
+	return sha256.Sum256(b)
+}
+
+// split returns the largest power of two smaller than n, for n > 1.
+func split(n int) int {
+	return 1 << (bits.Len(uint(n-1)) - 1)
+}
+
+// rootHash returns the root hash of the tree with leaves.
+func rootHash(leaves []Hash) Hash {
+	switch len(leaves) {
+	case 0:
+		return sha256.Sum256(nil)
+	case 1:
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return nodeHash(rootHash(leaves[:k]), rootHash(leaves[k:]))
+}
+
+// inclusionProof returns the hashes needed to recompute the root of the tree
+// with leaves from the leaf at index.
+func inclusionProof(leaves []Hash, index int) []Hash {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := split(len(leaves))
+	if index < k {
+		return append(inclusionProof(leaves[:k], index), rootHash(leaves[k:]))
+	}
+	return append(inclusionProof(leaves[k:], index-k), rootHash(leaves[:k]))
+}
+
+// consistencyProof returns the hashes needed to show that the tree with
+// leaves is an extension of the tree with its first m leaves.
+func consistencyProof(leaves []Hash, m int) []Hash {
+	return subproof(leaves, m, true)
+}
+
+func subproof(leaves []Hash, m int, complete bool) []Hash {
+	n := len(leaves)
+	if m == n {
+		if complete {
+			return nil
+		}
+		return []Hash{rootHash(leaves)}
+	}
+	k := split(n)
+	if m <= k {
+		return append(subproof(leaves[:k], m, complete), rootHash(leaves[k:]))
+	}
+	return append(subproof(leaves[k:], m-k, false), rootHash(leaves[:k]))
+}
+
+// ErrInvalidProof is returned when a proof doesn't prove what it claims to.
+var ErrInvalidProof = errors.New("invalid proof")
+
+// VerifyInclusion checks that proof shows the leaf with hash leaf is at
+// index in the tree of size with the given root.
+func VerifyInclusion(index, size int, leaf Hash, proof []Hash, root Hash) error {
+	if index < 0 || index >= size {
+		return fmt.Errorf("%w: leaf %d isn't in a tree of size %d", ErrInvalidProof, index, size)
+	}
+	fn, sn := index, size-1
+	r := leaf
+	for _, p := range proof {
+		if sn == 0 {
+			return fmt.Errorf("%w: too many hashes", ErrInvalidProof)
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn, sn = fn>>1, sn>>1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn, sn = fn>>1, sn>>1
+	}
+	if sn != 0 {
+		return fmt.Errorf("%w: too few hashes", ErrInvalidProof)
+	}
+	if r != root {
+		return fmt.Errorf("%w: root hash doesn't match", ErrInvalidProof)
+	}
+	return nil
+}
+
+// VerifyConsistency checks that proof shows the tree of size2 with root2
+// contains the tree of size1 with root1 as its first leaves.
+func VerifyConsistency(size1, size2 int, root1, root2 Hash, proof []Hash) error {
+	switch {
+	case size1 < 1 || size2 < size1:
+		return fmt.Errorf("%w: a tree of size %d can't extend a tree of size %d", ErrInvalidProof, size2, size1)
+	case size1 == size2:
+		if len(proof) != 0 {
+			return fmt.Errorf("%w: too many hashes", ErrInvalidProof)
+		}
+		if root1 != root2 {
+			return fmt.Errorf("%w: trees of the same size have different roots", ErrInvalidProof)
+		}
+		return nil
+	case len(proof) == 0:
+		return fmt.Errorf("%w: too few hashes", ErrInvalidProof)
+	}
+	if size1&(size1-1) == 0 {
+		proof = append([]Hash{root1}, proof...)
+	}
+	fn, sn := size1-1, size2-1
+	for fn&1 == 1 {
+		fn, sn = fn>>1, sn>>1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return fmt.Errorf("%w: too many hashes", ErrInvalidProof)
+		}
+		if fn&1 == 1 || fn == sn {
+			fr, sr = nodeHash(c, fr), nodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn, sn = fn>>1, sn>>1
+			}
+		} else {
+			sr = nodeHash(sr, c)
+		}
+		fn, sn = fn>>1, sn>>1
+	}
+	if sn != 0 {
+		return fmt.Errorf("%w: too few hashes", ErrInvalidProof)
+	}
+	if fr != root1 || sr != root2 {
+		return fmt.Errorf("%w: root hashes don't match", ErrInvalidProof)
+	}
+	return nil
+}
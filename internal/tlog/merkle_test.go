@@ -0,0 +1,59 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package tlog
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func testLeaves(n int) []Hash {
+	leaves := make([]Hash, n)
+	for i := range leaves {
+		leaves[i] = LeafHash([]byte(strconv.Itoa(i)))
+	}
+	return leaves
+}
+
+func TestInclusionProof(t *testing.T) {
+	leaves := testLeaves(20)
+	for size := 1; size <= len(leaves); size++ {
+		root := rootHash(leaves[:size])
+		for i := 0; i < size; i++ {
+			proof := inclusionProof(leaves[:size], i)
+			if err := VerifyInclusion(i, size, leaves[i], proof, root); err != nil {
+				t.Fatalf("leaf %d of %d: %s", i, size, err)
+			}
+			if err := VerifyInclusion(i, size, leaves[(i+1)%len(leaves)], proof, root); !errors.Is(err, ErrInvalidProof) {
+				t.Errorf("leaf %d of %d: got %v for the wrong leaf; want ErrInvalidProof", i, size, err)
+			}
+			if size > 1 {
+				if err := VerifyInclusion(i, size, leaves[i], proof[1:], root); !errors.Is(err, ErrInvalidProof) {
+					t.Errorf("leaf %d of %d: got %v for a short proof; want ErrInvalidProof", i, size, err)
+				}
+			}
+		}
+	}
+}
+
+func TestConsistencyProof(t *testing.T) {
+	leaves := testLeaves(20)
+	for size2 := 1; size2 <= len(leaves); size2++ {
+		root2 := rootHash(leaves[:size2])
+		for size1 := 1; size1 <= size2; size1++ {
+			root1 := rootHash(leaves[:size1])
+			proof := consistencyProof(leaves[:size2], size1)
+			if err := VerifyConsistency(size1, size2, root1, root2, proof); err != nil {
+				t.Fatalf("%d to %d: %s", size1, size2, err)
+			}
+			// A log which changed an earlier leaf isn't consistent.
+			changed := append([]Hash{}, leaves[:size2]...)
+			changed[0] = LeafHash([]byte("changed"))
+			if err := VerifyConsistency(size1, size2, root1, rootHash(changed), consistencyProof(changed, size1)); !errors.Is(err, ErrInvalidProof) {
+				t.Errorf("%d to %d: got %v for a changed log; want ErrInvalidProof", size1, size2, err)
+			}
+		}
+	}
+}
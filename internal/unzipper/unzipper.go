@@ -7,21 +7,74 @@ import (
 	"archive/zip"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
+	"github.com/hashicorp/actions-go-build/internal/filetree"
 	"github.com/hashicorp/actions-go-build/internal/log"
 )
 
+const (
+	dirMode  = 0o755
+	fileMode = 0o644
+	execMode = 0o755
+)
+
+// Limits protects against zip bombs. A zero field means no limit.
+type Limits struct {
+	// MaxEntries is the maximum number of entries in the zip.
+	MaxEntries int
+	// MaxTotalSize is the maximum number of bytes extracted, summed over
+	// all entries.
+	MaxTotalSize int64
+	// MaxRatio is the maximum ratio of uncompressed to compressed size
+	// for any single entry. It is only applied to entries larger than
+	// RatioThreshold, since small, repetitive files legitimately compress
+	// very well.
+	MaxRatio       uint64
+	RatioThreshold uint64
+}
+
+// DefaultLimits are comfortably larger than any real source archive.
+var DefaultLimits = Limits{
+	MaxEntries:     100_000,
+	MaxTotalSize:   4 << 30,
+	MaxRatio:       200,
+	RatioThreshold: 1 << 20,
+}
+
 type Unzipper struct {
-	log log.Func
+	log      log.Func
+	limits   Limits
+	symlinks bool
 }
 
-func New(logFunc log.Func) *Unzipper {
-	return &Unzipper{log: logFunc}
+// Option configures an Unzipper.
+type Option func(*Unzipper)
+
+// WithLimits replaces DefaultLimits.
+func WithLimits(l Limits) Option { return func(uz *Unzipper) { uz.limits = l } }
+
+// WithSymlinks allows symlink entries to be extracted, as long as their
+// targets are relative and stay inside the destination directory.
+// Without it, any symlink entry results in error.
+func WithSymlinks(on bool) Option { return func(uz *Unzipper) { uz.symlinks = on } }
+
+func New(logFunc log.Func, opts ...Option) *Unzipper {
+	uz := &Unzipper{log: logFunc, limits: DefaultLimits}
+	for _, o := range opts {
+		o(uz)
+	}
+	return uz
 }
 
+// Unzip extracts file into dest. Directories are always created with mode
+// 0755, and files with 0644, or 0755 if any executable bit is set in the zip.
+// Symlinks, if allowed, are created after all other entries so that nothing
+// is ever written through one.
 func (uz *Unzipper) Unzip(file, dest string) error {
 	r, err := zip.OpenReader(file)
 	if err != nil {
@@ -29,50 +82,182 @@ func (uz *Unzipper) Unzip(file, dest string) error {
 	}
 	var closeErr error
 	defer func() { closeErr = r.Close() }()
+	if err := uz.checkHeaders(r.File); err != nil {
+		return fmt.Errorf("refusing to extract %q: %w", file, err)
+	}
+	budget := uz.limits.MaxTotalSize
+	var links []*zip.File
 	for _, f := range r.File {
-		target := filepath.Join(dest, f.Name)
-		// Prevent directory traversal.
-		if !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) {
-			return fmt.Errorf("illegal file path %q", target)
+		target, err := targetPath(dest, f.Name)
+		if err != nil {
+			return err
 		}
-		if err := uz.unzipFile(target, f); err != nil {
+		if f.Mode()&fs.ModeSymlink != 0 {
+			if !uz.symlinks {
+				return fmt.Errorf("refusing to extract symlink %q", f.Name)
+			}
+			links = append(links, f)
+			continue
+		}
+		n, err := uz.unzipFile(target, f, budget)
+		if err != nil {
+			return err
+		}
+		budget -= n
+	}
+	for _, f := range links {
+		if err := uz.unzipSymlink(dest, f); err != nil {
 			return err
 		}
 	}
+	// Links can be chained so that each looks safe on its own, so they're
+	// only checked once they've all been created.
+	for _, f := range links {
+		if err := filetree.CheckInside(dest, path.Clean(f.Name)); err != nil {
+			uz.removeLinks(dest, links)
+			return fmt.Errorf("refusing to extract symlink %q pointing outside the destination: %w", f.Name, err)
+		}
+	}
 	return closeErr
 }
 
-func (uz *Unzipper) unzipFile(target string, f *zip.File) error {
+// checkHeaders applies the limits to the sizes declared in the zip's
+// headers, so obviously malicious zips are rejected before writing anything.
+// The sizes actually extracted are checked separately, since headers can lie.
+func (uz *Unzipper) checkHeaders(files []*zip.File) error {
+	l := uz.limits
+	if l.MaxEntries != 0 && len(files) > l.MaxEntries {
+		return fmt.Errorf("zip has %d entries, more than the limit of %d", len(files), l.MaxEntries)
+	}
+	var total uint64
+	for _, f := range files {
+		total += f.UncompressedSize64
+		if l.MaxTotalSize != 0 && total > uint64(l.MaxTotalSize) {
+			return fmt.Errorf("zip contents exceed the size limit of %d bytes", l.MaxTotalSize)
+		}
+		if l.MaxRatio == 0 || f.UncompressedSize64 <= l.RatioThreshold {
+			continue
+		}
+		if f.CompressedSize64 == 0 || f.UncompressedSize64/f.CompressedSize64 > l.MaxRatio {
+			return fmt.Errorf("entry %q has a compression ratio above the limit of %d", f.Name, l.MaxRatio)
+		}
+	}
+	return nil
+}
+
+// targetPath returns the path of name within dest, or an error if it would
+// be outside dest.
+func targetPath(dest, name string) (string, error) {
+	target := filepath.Join(dest, name)
+	// Prevent directory traversal.
+	if !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("illegal file path %q", target)
+	}
+	return target, nil
+}
+
+// unzipFile extracts a single file or directory, returning the number of bytes
+// written. If budget is positive, extracting more than budget bytes is an error.
+func (uz *Unzipper) unzipFile(target string, f *zip.File, budget int64) (int64, error) {
 	if f.FileInfo().IsDir() {
-		return os.MkdirAll(target, f.Mode())
+		return 0, os.MkdirAll(target, dirMode)
+	}
+	if !f.Mode().IsRegular() {
+		return 0, fmt.Errorf("refusing to extract %q: unsupported file mode %s", f.Name, f.Mode())
 	}
 
 	uz.log("Extracting file: %s", target)
-	if err := os.MkdirAll(filepath.Dir(target), f.Mode()); err != nil {
-		return err
+	if err := os.MkdirAll(filepath.Dir(target), dirMode); err != nil {
+		return 0, err
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+	var closeErr error
+	defer func() { closeErr = rc.Close() }()
+	var r io.Reader = rc
+	if uz.limits.MaxTotalSize != 0 {
+		// Read one byte more than the budget so we can tell if it was exceeded.
+		r = io.LimitReader(rc, budget+1)
+	}
+	mode := os.FileMode(fileMode)
+	if f.Mode()&0o111 != 0 {
+		mode = execMode
+	}
+	n, err := uz.writeFile(target, mode, r)
+	if err != nil {
+		return n, err
+	}
+	if uz.limits.MaxTotalSize != 0 && n > budget {
+		return n, fmt.Errorf("extracting %q: zip contents exceed the size limit of %d bytes", f.Name, uz.limits.MaxTotalSize)
 	}
+	return n, closeErr
+}
 
+func (uz *Unzipper) unzipSymlink(dest string, f *zip.File) error {
 	rc, err := f.Open()
 	if err != nil {
 		return err
 	}
 	var closeErr error
 	defer func() { closeErr = rc.Close() }()
-	if err := uz.writeFile(target, rc); err != nil {
+	// Symlink targets are short; anything longer than PATH_MAX is not one.
+	b, err := io.ReadAll(io.LimitReader(rc, 4096))
+	if err != nil {
+		return err
+	}
+	linkTarget := string(b)
+	name := path.Clean(f.Name)
+	if path.IsAbs(linkTarget) || filepath.IsAbs(linkTarget) {
+		return fmt.Errorf("refusing to extract symlink %q with absolute target %q", f.Name, linkTarget)
+	}
+	if resolved := path.Join(path.Dir(name), linkTarget); resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return fmt.Errorf("refusing to extract symlink %q pointing outside the destination: %q", f.Name, linkTarget)
+	}
+	// The lexical check above is only sound if no parent of the link is
+	// itself a symlink.
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		info, err := os.Lstat(filepath.Join(dest, filepath.FromSlash(dir)))
+		if err == nil && info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("refusing to extract symlink %q inside another symlink %q", f.Name, dir)
+		}
+	}
+	target, err := targetPath(dest, f.Name)
+	if err != nil {
+		return err
+	}
+	uz.log("Extracting symlink: %s -> %s", target, linkTarget)
+	if err := os.MkdirAll(filepath.Dir(target), dirMode); err != nil {
+		return err
+	}
+	if err := os.Symlink(linkTarget, target); err != nil {
 		return err
 	}
 	return closeErr
 }
 
-func (uz *Unzipper) writeFile(target string, r io.Reader) error {
-	t, err := os.Create(target)
+// removeLinks removes every symlink extracted from links, so that none
+// pointing outside dest are left behind.
+func (uz *Unzipper) removeLinks(dest string, links []*zip.File) {
+	for _, f := range links {
+		if target, err := targetPath(dest, f.Name); err == nil {
+			_ = os.Remove(target)
+		}
+	}
+}
+
+func (uz *Unzipper) writeFile(target string, mode os.FileMode, r io.Reader) (int64, error) {
+	t, err := os.OpenFile(target, os.O_RDWR|os.O_CREATE|os.O_TRUNC, mode)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	var closeErr error
 	defer func() { closeErr = t.Close() }()
-	if _, err := io.Copy(t, r); err != nil {
-		return err
+	n, err := io.Copy(t, r)
+	if err != nil {
+		return n, err
 	}
-	return closeErr
+	return n, closeErr
 }
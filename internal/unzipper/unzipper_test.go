@@ -0,0 +1,164 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package unzipper
+
+import (
+	"archive/zip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type entry struct {
+	name, contents string
+	mode           fs.FileMode
+}
+
+func TestUnzipper_Unzip_ok(t *testing.T) {
+	file := createZip(t, []entry{
+		{name: "repo-abc/", mode: fs.ModeDir | 0o700},
+		{name: "repo-abc/main.go", contents: "package main"},
+		{name: "repo-abc/docs/README", contents: "readme"},
+		{name: "repo-abc/README", contents: "docs/README", mode: fs.ModeSymlink | 0o777},
+	})
+	dest := t.TempDir()
+	if err := New(t.Logf, WithSymlinks(true)).Unzip(file, dest); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(dest, "repo-abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != dirMode {
+		t.Errorf("got dir mode %o; want %o", got, dirMode)
+	}
+	got, err := os.ReadFile(filepath.Join(dest, "repo-abc", "README"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "readme" {
+		t.Errorf("got README contents %q; want %q", got, "readme")
+	}
+}
+
+func TestUnzipper_Unzip_err(t *testing.T) {
+	big := strings.Repeat("a", 2<<20)
+	cases := []struct {
+		desc    string
+		entries []entry
+		opts    []Option
+		want    string
+	}{
+		{
+			"traversal",
+			[]entry{{name: "../evil", contents: "x"}},
+			nil,
+			"illegal file path",
+		},
+		{
+			"symlinks not allowed",
+			[]entry{{name: "link", contents: "target", mode: fs.ModeSymlink}},
+			nil,
+			`refusing to extract symlink "link"`,
+		},
+		{
+			"absolute symlink",
+			[]entry{{name: "link", contents: "/etc/passwd", mode: fs.ModeSymlink}},
+			[]Option{WithSymlinks(true)},
+			"absolute target",
+		},
+		{
+			"escaping symlink",
+			[]entry{{name: "a/link", contents: "../../x", mode: fs.ModeSymlink}},
+			[]Option{WithSymlinks(true)},
+			"pointing outside the destination",
+		},
+		{
+			"symlink through symlink",
+			[]entry{
+				{name: "a", contents: ".", mode: fs.ModeSymlink},
+				{name: "a/link", contents: "../x", mode: fs.ModeSymlink},
+			},
+			[]Option{WithSymlinks(true)},
+			"inside another symlink",
+		},
+		{
+			"chained symlinks",
+			[]entry{
+				{name: "b/c", contents: "..", mode: fs.ModeSymlink},
+				{name: "a", contents: "b/c/..", mode: fs.ModeSymlink},
+			},
+			[]Option{WithSymlinks(true)},
+			`refusing to extract symlink "a" pointing outside the destination`,
+		},
+		{
+			"chained symlinks, reversed",
+			[]entry{
+				{name: "a", contents: "b/c/..", mode: fs.ModeSymlink},
+				{name: "b/c", contents: "..", mode: fs.ModeSymlink},
+			},
+			[]Option{WithSymlinks(true)},
+			`refusing to extract symlink "a" pointing outside the destination`,
+		},
+		{
+			"too many entries",
+			[]entry{{name: "a"}, {name: "b"}, {name: "c"}},
+			[]Option{WithLimits(Limits{MaxEntries: 2})},
+			"zip has 3 entries, more than the limit of 2",
+		},
+		{
+			"too big",
+			[]entry{{name: "a", contents: "1234"}, {name: "b", contents: "5678"}},
+			[]Option{WithLimits(Limits{MaxTotalSize: 6})},
+			"exceed the size limit of 6 bytes",
+		},
+		{
+			"compression ratio",
+			[]entry{{name: "bomb", contents: big}},
+			nil,
+			`entry "bomb" has a compression ratio above the limit of 200`,
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			file := createZip(t, c.entries)
+			err := New(t.Logf, c.opts...).Unzip(file, t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("got error %v; want error containing %q", err, c.want)
+			}
+		})
+	}
+}
+
+func createZip(t *testing.T, entries []entry) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "test.zip")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, e := range entries {
+		h := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		if e.mode == 0 {
+			e.mode = 0o644
+		}
+		h.SetMode(e.mode)
+		w, err := zw.CreateHeader(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(e.contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
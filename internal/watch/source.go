@@ -0,0 +1,138 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/actions-go-build/internal/fetch"
+)
+
+// Source lists and reads published build result documents.
+type Source interface {
+	// List returns the locations of every document, e.g. paths or URLs.
+	List(ctx context.Context) ([]string, error)
+	// Read returns the document at location.
+	Read(ctx context.Context, location string) ([]byte, error)
+}
+
+// IsIndex returns true if location is the URL of an index, rather than a
+// directory.
+func IsIndex(location string) bool {
+	u, err := url.Parse(location)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// NewSource returns a DirSource if location is a directory, or an
+// IndexSource if it's an https URL.
+func NewSource(location string, client *fetch.Client, token func(host string) fetch.Token) (Source, error) {
+	if IsIndex(location) {
+		u, err := url.Parse(location)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "https" {
+			return nil, fmt.Errorf("index URLs must use https scheme")
+		}
+		return IndexSource{URL: u, Client: client, Token: token}, nil
+	}
+	info, err := os.Stat(location)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory or https URL", location)
+	}
+	return DirSource(location), nil
+}
+
+// DirSource is a directory which is searched for .json files.
+type DirSource string
+
+// List returns the path to every .json file in the directory, in order.
+func (d DirSource) List(context.Context) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(string(d), func(path string, e fs.DirEntry, err error) error {
+		if err == nil && !e.IsDir() && filepath.Ext(path) == ".json" {
+			paths = append(paths, path)
+		}
+		return err
+	})
+	return paths, err
+}
+
+func (d DirSource) Read(_ context.Context, path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// IndexSource is an index listing the URLs of documents, either as a JSON
+// list, or one per line. Relative URLs are resolved against the index's URL.
+type IndexSource struct {
+	URL    *url.URL
+	Client *fetch.Client
+	// Token returns the token to send to host.
+	Token func(host string) fetch.Token
+}
+
+func (s IndexSource) List(ctx context.Context) ([]string, error) {
+	data, err := s.Read(ctx, s.URL.String())
+	if err != nil {
+		return nil, err
+	}
+	return parseIndex(s.URL, data)
+}
+
+func (s IndexSource) Read(ctx context.Context, location string) ([]byte, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	var token fetch.Token
+	if s.Token != nil {
+		token = s.Token(u.Host)
+	}
+	rc, err := s.Client.Get(ctx, location, token)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func parseIndex(base *url.URL, data []byte) ([]string, error) {
+	var refs []string
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(data, &refs); err != nil {
+			return nil, fmt.Errorf("reading index %s: %w", base, err)
+		}
+	} else {
+		for _, line := range strings.Split(trimmed, "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				refs = append(refs, line)
+			}
+		}
+	}
+	urls := make([]string, len(refs))
+	for i, ref := range refs {
+		u, err := base.Parse(ref)
+		if err != nil {
+			return nil, fmt.Errorf("reading index %s: %w", base, err)
+		}
+		if u.Scheme != "https" {
+			return nil, fmt.Errorf("reading index %s: %s doesn't use https scheme", base, u)
+		}
+		urls[i] = u.String()
+	}
+	sort.Strings(urls)
+	return urls, nil
+}
@@ -0,0 +1,331 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+// Package watch continuously re-verifies published build results, and
+// reports regressions: results which once reproduced, but no longer do.
+package watch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/internal/atomicfile"
+	"github.com/hashicorp/actions-go-build/internal/log"
+	"github.com/hashicorp/actions-go-build/internal/signing"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	"github.com/hashicorp/composite-action-framework-go/pkg/json"
+)
+
+// Outcome is the outcome of verifying a build result.
+type Outcome string
+
+const (
+	// OutcomeReproduced means the verification build reproduced the result.
+	OutcomeReproduced Outcome = "reproduced"
+	// OutcomeNotReproduced means the verification build produced different
+	// artifacts, e.g. because the source archive changed.
+	OutcomeNotReproduced Outcome = "not reproduced"
+	// OutcomeFailed means the result couldn't be verified, e.g. because
+	// the verification build failed.
+	OutcomeFailed Outcome = "failed"
+)
+
+// Check is a single verification of a build result.
+type Check struct {
+	Time    time.Time
+	Outcome Outcome
+	Error   string `json:",omitempty"`
+	// Regression is true if the result reproduced before, but didn't now,
+	// either because the artifacts differ or because it couldn't be
+	// verified at all, e.g. because the source archive changed.
+	Regression bool `json:",omitempty"`
+	// Result and Log are the paths to the verification result and the
+	// logs of the builds, relative to the state directory.
+	Result string `json:",omitempty"`
+	Log    string
+}
+
+// Record is everything known about a single build result.
+type Record struct {
+	// Location is where the result was last seen.
+	Location  string
+	Product   string `json:",omitempty"`
+	Version   string `json:",omitempty"`
+	Platform  string `json:",omitempty"`
+	FirstSeen time.Time
+	// Skipped is why the document isn't being verified, e.g. because it
+	// isn't a build result.
+	Skipped string `json:",omitempty"`
+	// LastReproduced is when the result was last reproduced, which is kept
+	// even once the check that reproduced it is no longer in Checks.
+	LastReproduced *time.Time `json:",omitempty"`
+	// Checks are the latest checks, oldest first.
+	Checks []Check `json:",omitempty"`
+}
+
+// Last returns the latest check, and false if there hasn't been one.
+func (r Record) Last() (Check, bool) {
+	if len(r.Checks) == 0 {
+		return Check{}, false
+	}
+	return r.Checks[len(r.Checks)-1], true
+}
+
+func (r Record) describe() string {
+	return fmt.Sprintf("%s %s %s (%s)", r.Product, r.Version, r.Platform, r.Location)
+}
+
+// State is every record, keyed by the SHA-256 of the document, so that a
+// document is new if its contents change, but not if it moves.
+type State struct {
+	Records map[string]*Record
+}
+
+// maxChecks is how many checks are kept for each record.
+const maxChecks = 20
+
+const stateFile = "state.json"
+
+// VerifyFunc runs a verification build of a build result and compares it
+// with that result, writing the logs and the build output to log.
+type VerifyFunc func(ctx context.Context, r build.Result, log io.Writer) (*build.VerificationResult, error)
+
+// OpenFunc returns the document read from location, checking its signature
+// if it's signed. It returns an error if the document isn't trusted.
+type OpenFunc func(location string, data []byte) ([]byte, error)
+
+// Watcher polls a Source for new build results and verifies them. Don't use
+// the zero Watcher, use New.
+type Watcher struct {
+	source  Source
+	dir     string
+	verify  VerifyFunc
+	open    OpenFunc
+	recheck time.Duration
+	log     log.Func
+	now     func() time.Time
+	state   State
+}
+
+// Option configures a Watcher.
+type Option func(*Watcher)
+
+// WithRecheck sets how long after a result was last verified it's verified
+// again. Zero means results are only verified once.
+func WithRecheck(d time.Duration) Option { return func(w *Watcher) { w.recheck = d } }
+
+// WithLog sets where the watcher logs.
+func WithLog(f log.Func) Option { return func(w *Watcher) { w.log = f } }
+
+// WithOpen sets how documents are opened. By default, every document is
+// trusted, and signed ones are read without checking their signatures.
+func WithOpen(f OpenFunc) Option { return func(w *Watcher) { w.open = f } }
+
+// New returns a Watcher keeping its state in dir.
+func New(source Source, dir string, verify VerifyFunc, opts ...Option) (*Watcher, error) {
+	w := &Watcher{
+		source: source,
+		dir:    dir,
+		verify: verify,
+		open:   signing.Trust{}.Open,
+		log:    func(string, ...any) {},
+		now:    time.Now,
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var err error
+	w.state, err = json.ReadFile[State](filepath.Join(dir, stateFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+	}
+	if w.state.Records == nil {
+		w.state.Records = map[string]*Record{}
+	}
+	return w, err
+}
+
+// State returns the watcher's state.
+func (w *Watcher) State() State { return w.state }
+
+// Regression is a result which reproduced before, but no longer does.
+type Regression struct {
+	Record Record
+	Check  Check
+}
+
+// Summary describes a single poll.
+type Summary struct {
+	New, Checked int
+	Regressions  []Regression
+}
+
+// Poll verifies every new result in the source, and every result last
+// verified longer ago than the recheck interval. Errors reading individual
+// documents are logged, and don't stop the poll.
+func (w *Watcher) Poll(ctx context.Context) (Summary, error) {
+	var s Summary
+	locations, err := w.source.List(ctx)
+	if err != nil {
+		return s, err
+	}
+	for _, location := range locations {
+		if err := ctx.Err(); err != nil {
+			return s, err
+		}
+		data, err := w.source.Read(ctx, location)
+		if err != nil {
+			w.log("Reading %s: %s", location, err)
+			continue
+		}
+		key := digest(data)
+		// Untrusted documents aren't recorded, so that they're picked up
+		// if the trusted keys change.
+		doc, err := w.open(location, data)
+		if err != nil {
+			w.log("Skipping %s: %s", location, err)
+			continue
+		}
+		rec, seen := w.state.Records[key]
+		if !seen {
+			s.New++
+			rec = w.newRecord(location, doc)
+			w.state.Records[key] = rec
+			if rec.Skipped != "" {
+				w.log("Skipping %s: %s", location, rec.Skipped)
+			}
+		}
+		rec.Location = location
+		if rec.Skipped != "" || !w.due(*rec) {
+			if !seen {
+				if err := w.save(); err != nil {
+					return s, err
+				}
+			}
+			continue
+		}
+		r, err := build.ReadDocument[build.Result](doc)
+		if err != nil {
+			return s, err
+		}
+		check, err := w.check(ctx, key, rec, r)
+		if err != nil {
+			return s, err
+		}
+		s.Checked++
+		if check.Regression {
+			s.Regressions = append(s.Regressions, Regression{Record: *rec, Check: check})
+		}
+	}
+	return s, nil
+}
+
+// Run polls every interval until ctx is done, calling report after each
+// successful poll.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, report func(Summary)) error {
+	for {
+		s, err := w.Poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			w.log("Polling failed: %s", err)
+		} else if err == nil {
+			report(s)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (w *Watcher) newRecord(location string, data []byte) *Record {
+	rec := &Record{Location: location, FirstSeen: w.now().UTC()}
+	r, err := build.ReadDocument[build.Result](data)
+	if err != nil {
+		rec.Skipped = err.Error()
+		return rec
+	}
+	c := r.Config
+	rec.Product, rec.Version = c.Product.Name, c.Product.Version.Full
+	rec.Platform = c.Parameters.OS + "/" + c.Parameters.Arch
+	switch {
+	case !r.Successful:
+		rec.Skipped = "the build failed"
+	case c.Product.IsDirty():
+		rec.Skipped = "dirty builds can't be verified remotely"
+	}
+	return rec
+}
+
+func (w *Watcher) due(rec Record) bool {
+	last, ok := rec.Last()
+	return !ok || (w.recheck > 0 && w.now().Sub(last.Time) >= w.recheck)
+}
+
+// check verifies r, and records the outcome in rec.
+func (w *Watcher) check(ctx context.Context, key string, rec *Record, r build.Result) (Check, error) {
+	now := w.now().UTC()
+	name := filepath.Join("checks", key, now.Format("20060102T150405Z"))
+	c := Check{Time: now, Log: name + ".log"}
+	if err := os.MkdirAll(filepath.Join(w.dir, "checks", key), 0o755); err != nil {
+		return c, err
+	}
+	logw, err := os.Create(filepath.Join(w.dir, c.Log))
+	if err != nil {
+		return c, err
+	}
+	defer logw.Close()
+
+	w.log("Verifying %s", rec.describe())
+	vr, err := w.verify(ctx, r, logw)
+	if ctx.Err() != nil {
+		// Don't record interrupted checks, so they're run again next time.
+		return c, ctx.Err()
+	}
+	switch {
+	case err != nil:
+		c.Outcome, c.Error = OutcomeFailed, err.Error()
+	case vr == nil:
+		c.Outcome, c.Error = OutcomeFailed, "no verification result"
+	case vr.ReproducedCorrectly:
+		c.Outcome = OutcomeReproduced
+	default:
+		c.Outcome, c.Error = OutcomeNotReproduced, vr.ErrorMessage
+	}
+	if vr != nil {
+		c.Result = name + ".json"
+		if err := build.WriteDocumentFile(filepath.Join(w.dir, c.Result), vr); err != nil {
+			return c, err
+		}
+	}
+	c.Regression = c.Outcome != OutcomeReproduced && rec.LastReproduced != nil
+	if c.Outcome == OutcomeReproduced {
+		rec.LastReproduced = &now
+	}
+	w.log("%s: %s", rec.describe(), c.Outcome)
+	rec.Checks = append(rec.Checks, c)
+	if n := len(rec.Checks); n > maxChecks {
+		rec.Checks = rec.Checks[n-maxChecks:]
+	}
+	return c, w.save()
+}
+
+func (w *Watcher) save() error {
+	return atomicfile.WriteJSON(filepath.Join(w.dir, stateFile), w.state)
+}
@@ -0,0 +1,279 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package watch
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/internal/signing"
+	"github.com/hashicorp/actions-go-build/internal/testhelpers/buildtest"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+// namedResult returns a result for the product name, so results can be
+// told apart.
+func namedResult(name string) build.Result {
+	r := buildtest.Result()
+	r.Config.Product.Name = name
+	return r
+}
+
+// fakeVerifier reproduces results until reproduces is set to false, and
+// fails with err if it's set.
+type fakeVerifier struct {
+	reproduces bool
+	err        error
+	calls      int
+}
+
+func (f *fakeVerifier) verify(_ context.Context, r build.Result, w io.Writer) (*build.VerificationResult, error) {
+	f.calls++
+	fmt.Fprintf(w, "verifying %s\n", r.Config.Product.Name)
+	if f.err != nil {
+		return nil, f.err
+	}
+	vr := &build.VerificationResult{Primary: &r, Verification: &r, ReproducedCorrectly: f.reproduces}
+	if !f.reproduces {
+		vr.ErrorMessage = "binaries differ"
+	}
+	return vr, nil
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	src := tmp.Dir(t)
+	state := tmp.Dir(t)
+	buildtest.WriteDocument(t, filepath.Join(src, "a.json"), namedResult("lockbox"))
+	failed := namedResult("failing")
+	failed.Successful = false
+	buildtest.WriteDocument(t, filepath.Join(src, "failed.json"), failed)
+	dirty := namedResult("dirty")
+	dirty.Config.Product.SourceHash = "d1rty"
+	buildtest.WriteDocument(t, filepath.Join(src, "dirty.json"), dirty)
+	buildtest.WriteDocument(t, filepath.Join(src, "config.json"), namedResult("config").Config)
+	if err := os.WriteFile(filepath.Join(src, "notes.txt"), []byte("ignored"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := &fakeVerifier{reproduces: true}
+	newWatcher := func() *Watcher {
+		w, err := New(DirSource(src), state, v.verify, WithRecheck(24*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.now = func() time.Time { return now }
+		return w
+	}
+	w := newWatcher()
+	ctx := context.Background()
+
+	s, err := w.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.New != 4 || s.Checked != 1 || len(s.Regressions) != 0 {
+		t.Fatalf("got first poll %+v; want 4 new, 1 checked, no regressions", s)
+	}
+	records := w.State().Records
+	skipped := 0
+	for _, rec := range records {
+		if rec.Skipped != "" {
+			skipped++
+		}
+	}
+	if skipped != 3 {
+		t.Errorf("got %d skipped records; want 3", skipped)
+	}
+
+	// Nothing is due until the recheck interval passes, even after a restart.
+	now = now.Add(time.Hour)
+	w = newWatcher()
+	if s, err = w.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if s.New != 0 || s.Checked != 0 || v.calls != 1 {
+		t.Fatalf("got second poll %+v after %d calls; want nothing new or checked", s, v.calls)
+	}
+
+	// The source archive changes, so the result no longer reproduces.
+	now = now.Add(24 * time.Hour)
+	v.reproduces = false
+	if s, err = w.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if s.Checked != 1 || len(s.Regressions) != 1 {
+		t.Fatalf("got third poll %+v; want 1 checked, 1 regression", s)
+	}
+	reg := s.Regressions[0]
+	if reg.Record.Product != "lockbox" || reg.Check.Outcome != OutcomeNotReproduced || reg.Check.Error != "binaries differ" {
+		t.Errorf("got regression %+v", reg)
+	}
+	for _, name := range []string{reg.Check.Log, reg.Check.Result} {
+		if _, err := os.Stat(filepath.Join(state, name)); err != nil {
+			t.Errorf("check file: %s", err)
+		}
+	}
+	if got := len(reg.Record.Checks); got != 2 {
+		t.Errorf("got %d checks; want 2", got)
+	}
+}
+
+func TestWatcher_Poll_failedAfterReproducing(t *testing.T) {
+	src := tmp.Dir(t)
+	buildtest.WriteDocument(t, filepath.Join(src, "a.json"), namedResult("lockbox"))
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := &fakeVerifier{reproduces: true}
+	w, err := New(DirSource(src), tmp.Dir(t), v.verify, WithRecheck(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.now = func() time.Time { return now }
+	ctx := context.Background()
+	if _, err := w.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The source archive changes, so the verification build can't even start.
+	now = now.Add(time.Hour)
+	v.err = errors.New("source tree hash mismatch")
+	s, err := w.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Checked != 1 || len(s.Regressions) != 1 {
+		t.Fatalf("got %+v; want 1 checked, 1 regression", s)
+	}
+	if c := s.Regressions[0].Check; c.Outcome != OutcomeFailed || c.Error != "source tree hash mismatch" {
+		t.Errorf("got check %+v", c)
+	}
+}
+
+func TestWatcher_Poll_neverReproduced(t *testing.T) {
+	src := tmp.Dir(t)
+	buildtest.WriteDocument(t, filepath.Join(src, "a.json"), namedResult("lockbox"))
+	v := &fakeVerifier{}
+	w, err := New(DirSource(src), tmp.Dir(t), v.verify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := w.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Results that never reproduced aren't regressions.
+	if s.Checked != 1 || len(s.Regressions) != 0 {
+		t.Fatalf("got %+v; want 1 checked, no regressions", s)
+	}
+}
+
+func TestWatcher_Poll_signed(t *testing.T) {
+	src := tmp.Dir(t)
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := build.MarshalDocument(namedResult("lockbox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := json.Marshal(signing.Sign(key, doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "a.json"), signed, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	v := &fakeVerifier{reproduces: true}
+	w, err := New(DirSource(src), tmp.Dir(t), v.verify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := w.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Checked != 1 {
+		t.Fatalf("got %+v; want the signed result checked", s)
+	}
+}
+
+func TestWatcher_Poll_untrusted(t *testing.T) {
+	src := tmp.Dir(t)
+	data, err := build.MarshalDocument(namedResult("lockbox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "signed.json"), append([]byte("signed:"), data...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	buildtest.WriteDocument(t, filepath.Join(src, "unsigned.json"), namedResult("unsigned"))
+	open := func(location string, data []byte) ([]byte, error) {
+		if !bytes.HasPrefix(data, []byte("signed:")) {
+			return nil, fmt.Errorf("%s isn't signed", location)
+		}
+		return bytes.TrimPrefix(data, []byte("signed:")), nil
+	}
+	v := &fakeVerifier{reproduces: true}
+	w, err := New(DirSource(src), tmp.Dir(t), v.verify, WithOpen(open))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := w.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Untrusted documents are neither verified nor recorded.
+	if s.New != 1 || s.Checked != 1 || len(w.State().Records) != 1 {
+		t.Fatalf("got %+v with %d records; want 1 new, 1 checked, 1 record", s, len(w.State().Records))
+	}
+	for _, rec := range w.State().Records {
+		if rec.Product != "lockbox" {
+			t.Errorf("got record for %q; want lockbox", rec.Product)
+		}
+	}
+}
+
+func TestParseIndex(t *testing.T) {
+	base, err := url.Parse("https://example.com/builds/index.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"https://example.com/builds/linux.json",
+		"https://example.com/darwin.json",
+		"https://other.example.com/windows.json",
+	}
+	for name, index := range map[string]string{
+		"json":  `["linux.json", "/darwin.json", "https://other.example.com/windows.json"]`,
+		"lines": "# builds\nlinux.json\n\n/darwin.json\nhttps://other.example.com/windows.json\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseIndex(base, []byte(index))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %q; want %q", got, want)
+			}
+		})
+	}
+
+	if _, err := parseIndex(base, []byte("http://example.com/linux.json")); err == nil {
+		t.Error("got nil error for http URL")
+	}
+}
@@ -5,50 +5,101 @@ package zipper
 
 import (
 	"archive/zip"
+	"compress/flate"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
-	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/internal/filetree"
+)
+
+const (
+	fileMode    = 0o644
+	execMode    = 0o755
+	dirMode     = 0o755
+	symlinkMode = 0o777
+
+	// compressionLevel is fixed so that zips don't change if the
+	// zip package's default level ever does.
+	compressionLevel = flate.BestCompression
 )
 
 type Zipper struct {
-	dir     string
-	written map[string]struct{}
-	zw      *zip.Writer
-	log     func(string, ...any)
+	dir           string
+	written       map[string]struct{}
+	zw            *zip.Writer
+	log           func(string, ...any)
+	preservePaths bool
+	modDate       uint16
+	modTime       uint16
+}
+
+// Option configures a Zipper.
+type Option func(*Zipper)
+
+// WithPreservePaths keeps each file's path relative to the zipped directory,
+// rather than flattening the hierarchy. Directories get their own entries,
+// and symlinks are stored as symlinks if they point inside the directory.
+func WithPreservePaths(on bool) Option { return func(z *Zipper) { z.preservePaths = on } }
+
+// WithModTime sets the modification time written to every entry. Without
+// it, every entry is stamped with the earliest time a zip can represent,
+// 1980-01-01 00:00:00.
+func WithModTime(t time.Time) Option {
+	return func(z *Zipper) { z.modDate, z.modTime = msDosTime(t) }
+}
+
+// msDosTime converts t to the MS-DOS date and time fields used in zip
+// headers. These have no time zone and a resolution of two seconds.
+func msDosTime(t time.Time) (date, tm uint16) {
+	t = t.UTC()
+	if t.Year() < 1980 {
+		t = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	date = uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9)
+	tm = uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)
+	return date, tm
 }
 
 // New returns a new zipper configured to zip the contents of dir.
-func New(w io.Writer, logFunc func(string, ...any)) *Zipper {
-	return &Zipper{
+func New(w io.Writer, logFunc func(string, ...any), opts ...Option) *Zipper {
+	z := &Zipper{
 		written: map[string]struct{}{},
 		zw:      zip.NewWriter(w),
 		log:     logFunc,
 	}
+	z.modDate, z.modTime = msDosTime(time.Time{})
+	for _, o := range opts {
+		o(z)
+	}
+	z.zw.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, compressionLevel)
+	})
+	return z
 }
 
 // ZipDir zips the contents of dir to the provided writer, and flattens any
 // directory hierarchy, so the resultant zip has just a flat list of
 // files. Filename conflicts result in error.
 // It aims to produce reproducible zips by writing entries in a predictable
-// order.
+// order, and by normalizing every header: see createHeader.
 //
 // It is intended to perform the same function as calling 'zip -Xrj $zipFile $dir'
+// or, when using WithPreservePaths, 'cd $dir && zip -Xry $zipFile .'
 func (z *Zipper) ZipDir(dir string) error {
 	z.log("Zipping %q", dir)
-	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
+	if err := filetree.Walk(dir, z.preservePaths, func(e filetree.Entry) error {
+		z.log("Adding %q to zip file, from %q", e.Name, e.Path)
+		switch e.Type {
+		case filetree.Dir:
+			return z.writeDir(e.Name)
+		case filetree.Symlink:
+			return z.writeSymlink(e.Name, e.LinkTarget)
 		}
-		name := filepath.Base(path)
 
-		z.log("Adding %q to zip file, from %q", name, path)
-
-		source, err := os.Open(path)
+		source, err := os.Open(e.Path)
 		if err != nil {
 			return err
 		}
@@ -58,7 +109,7 @@ func (z *Zipper) ZipDir(dir string) error {
 				closeErr = err
 			}
 		}()
-		if err := z.writeEntry(name, source); err != nil {
+		if err := z.writeEntry(e.Name, source); err != nil {
 			return err
 		}
 		return closeErr
@@ -79,28 +130,61 @@ func (z *Zipper) writeEntry(name string, source *os.File) error {
 	if err != nil {
 		return err
 	}
-	header, err := zip.FileInfoHeader(info)
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%q is not a regular file", source.Name())
+	}
+	mode := fs.FileMode(fileMode)
+	if info.Mode()&0o111 != 0 {
+		mode = execMode
+	}
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+	header.SetMode(mode)
+	entry, err := z.createHeader(header)
 	if err != nil {
 		return err
 	}
-	if _, ok := z.written[header.Name]; ok {
-		return fmt.Errorf("duplicate entry %q", name)
-	}
+	_, err = io.Copy(entry, source)
+	return err
+}
 
-	header.Method = zip.Deflate
+func (z *Zipper) writeDir(name string) error {
+	header := &zip.FileHeader{Name: name, Method: zip.Store}
+	header.SetMode(fs.ModeDir | dirMode)
+	_, err := z.createHeader(header)
+	return err
+}
 
-	z.written[name] = struct{}{}
-	entry, err := z.zw.CreateHeader(header)
+func (z *Zipper) writeSymlink(name, target string) error {
+	header := &zip.FileHeader{Name: name, Method: zip.Store}
+	header.SetMode(fs.ModeSymlink | symlinkMode)
+	entry, err := z.createHeader(header)
 	if err != nil {
 		return err
 	}
-	_, err = io.Copy(entry, source)
+	_, err = io.WriteString(entry, target)
 	return err
 }
 
+// createHeader writes header, after normalizing the fields which would
+// otherwise depend on the host. Modified is left zero and the MS-DOS time
+// fields are set directly, because a non-zero Modified makes the zip package
+// add an extended timestamp extra field. No other extra fields are written,
+// so there is no uid or gid either.
+func (z *Zipper) createHeader(header *zip.FileHeader) (io.Writer, error) {
+	if _, ok := z.written[header.Name]; ok {
+		return nil, fmt.Errorf("duplicate entry %q", header.Name)
+	}
+	z.written[header.Name] = struct{}{}
+	header.Modified = time.Time{}
+	header.ModifiedDate, header.ModifiedTime = z.modDate, z.modTime
+	header.Extra = nil
+	header.Comment = ""
+	return z.zw.CreateHeader(header)
+}
+
 // ZipToFile is a convenience function meant to be equivalent to using the command line:
 // 'zip -Xrj $zipFile $dir`
-func ZipToFile(dir, zipFile string, logFunc func(string, ...any)) error {
+func ZipToFile(dir, zipFile string, logFunc func(string, ...any), opts ...Option) error {
 	f, err := os.Create(zipFile)
 	if err != nil {
 		return err
@@ -108,7 +192,7 @@ func ZipToFile(dir, zipFile string, logFunc func(string, ...any)) error {
 	var closeErr error
 	defer func() { closeErr = f.Close() }()
 
-	z := New(f, logFunc)
+	z := New(f, logFunc, opts...)
 
 	if err := z.ZipDir(dir); err != nil {
 		return err
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/composite-action-framework-go/pkg/fs"
 )
@@ -106,6 +107,123 @@ func TestZipper_ZipDir_err_duplicate(t *testing.T) {
 
 }
 
+func TestZipper_ZipDir_preservePaths(t *testing.T) {
+	dir := createTestDir(t, files{
+		"bin/lockbox":       "binary",
+		"share/doc/README":  "readme",
+		"subdir/text.txt":   "hello!",
+		"other/subdir/text": "hello!",
+	})
+	if err := os.Symlink("../bin/lockbox", filepath.Join(dir, "share", "lockbox")); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	z := New(buf, t.Logf, WithPreservePaths(true))
+	if err := z.ZipDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, f := range reader.File {
+		got = append(got, f.Name)
+		if f.Name == "share/lockbox" && f.Mode()&os.ModeSymlink == 0 {
+			t.Errorf("got mode %s for %q; want symlink", f.Mode(), f.Name)
+		}
+	}
+	want := []string{
+		"bin/", "bin/lockbox",
+		"other/", "other/subdir/", "other/subdir/text",
+		"share/", "share/doc/", "share/doc/README", "share/lockbox",
+		"subdir/", "subdir/text.txt",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got entries %q; want %q", got, want)
+	}
+}
+
+func TestZipper_ZipDir_err_unsafeSymlink(t *testing.T) {
+	for _, target := range []string{"/etc/passwd", "../../outside"} {
+		target := target
+		t.Run("", func(t *testing.T) {
+			dir := createTestDir(t, files{"sub/text.txt": "hello!"})
+			if err := os.Symlink(target, filepath.Join(dir, "sub", "link")); err != nil {
+				t.Fatal(err)
+			}
+			err := New(&bytes.Buffer{}, t.Logf, WithPreservePaths(true)).ZipDir(dir)
+			if err == nil || !strings.Contains(err.Error(), "symlink") {
+				t.Fatalf("got error %v; want unsafe symlink error", err)
+			}
+		})
+	}
+}
+
+func TestZipper_ZipDir_err_chainedSymlink(t *testing.T) {
+	dir := createTestDir(t, files{"sub/text.txt": "hello!"})
+	// Each target looks safe on its own, but a resolves to dir's parent.
+	if err := os.Symlink("..", filepath.Join(dir, "sub", "c")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("sub/c/..", filepath.Join(dir, "a")); err != nil {
+		t.Fatal(err)
+	}
+	err := New(&bytes.Buffer{}, t.Logf, WithPreservePaths(true)).ZipDir(dir)
+	if err == nil || !strings.Contains(err.Error(), `symlink "a" points outside`) {
+		t.Fatalf("got error %v; want unsafe symlink error", err)
+	}
+}
+
+func TestZipper_ZipDir_normalizedHeaders(t *testing.T) {
+	modTime := time.Date(2022, 7, 4, 11, 33, 33, 0, time.UTC)
+	var zips [][]byte
+	for i, mode := range []os.FileMode{0o600, 0o644, 0o664} {
+		dir := createTestDir(t, files{"lockbox": "binary", "README": "readme"})
+		if err := os.Chmod(filepath.Join(dir, "lockbox"), 0o700|mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(filepath.Join(dir, "README"), mode); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(filepath.Join(dir, "README"), mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		buf := &bytes.Buffer{}
+		if err := New(buf, t.Logf, WithModTime(modTime)).ZipDir(dir); err != nil {
+			t.Fatal(err)
+		}
+		zips = append(zips, buf.Bytes())
+	}
+	for _, z := range zips[1:] {
+		if !bytes.Equal(z, zips[0]) {
+			t.Fatal("got differing zips; want all identical")
+		}
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(zips[0]), int64(len(zips[0])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantModes := map[string]os.FileMode{"lockbox": 0o755, "README": 0o644}
+	// MS-DOS times have two second resolution.
+	wantTime := modTime.Truncate(2 * time.Second)
+	for _, f := range reader.File {
+		if f.Mode() != wantModes[f.Name] {
+			t.Errorf("%s: got mode %s; want %s", f.Name, f.Mode(), wantModes[f.Name])
+		}
+		if !f.Modified.Equal(wantTime) {
+			t.Errorf("%s: got mtime %s; want %s", f.Name, f.Modified, wantTime)
+		}
+		if len(f.Extra) != 0 {
+			t.Errorf("%s: got extra fields %x; want none", f.Name, f.Extra)
+		}
+	}
+}
+
 func createTestDir(t *testing.T, f files) string {
 	t.Helper()
 	pathSegments := strings.Split(t.Name(), "/")
@@ -43,11 +43,17 @@ func makeCLI(thisTool crt.Product, args []string) *cli.CLI {
 	c.Args = args
 
 	c.Commands = map[string]cli.CommandFactory{
-		"build":   makeCommand(commands.Build),
-		"config":  makeCommand(commands.Config),
-		"inspect": makeCommand(commands.Inspect),
-		"verify":  makeCommand(commands.Verify),
-		"version": makeCommand(versionCommand),
+		"build":         makeCommand(commands.Build),
+		"compare":       makeCommand(commands.Compare),
+		"config":        makeCommand(commands.Config),
+		"inspect":       makeCommand(commands.Inspect),
+		"rebuild-watch": makeCommand(commands.RebuildWatch),
+		"report":        makeCommand(commands.Report),
+		"serve":         makeCommand(commands.Serve),
+		"sign":          makeCommand(commands.Sign),
+		"tlog":          makeCommand(commands.TLog),
+		"verify":        makeCommand(commands.Verify),
+		"version":       makeCommand(versionCommand),
 	}
 
 	return c
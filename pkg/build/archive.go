@@ -0,0 +1,76 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/internal/tarball"
+	"github.com/hashicorp/actions-go-build/internal/zipper"
+)
+
+// Supported values for Parameters.ArchiveFormat. Each is also the file
+// extension used for the default archive name.
+const (
+	ArchiveZip   = "zip"
+	ArchiveTarGz = "tar.gz"
+	ArchiveTarXz = "tar.xz"
+)
+
+// archiveSpec describes a single archive to create.
+type archiveSpec struct {
+	dir, file     string
+	modTime       time.Time
+	preservePaths bool
+	log           func(string, ...any)
+}
+
+type archiveFunc func(archiveSpec) error
+
+var archiveFormats = map[string]archiveFunc{
+	ArchiveZip: func(s archiveSpec) error {
+		return zipper.ZipToFile(s.dir, s.file, s.log,
+			zipper.WithModTime(s.modTime), zipper.WithPreservePaths(s.preservePaths))
+	},
+	ArchiveTarGz: tarballFunc(tarball.Gzip),
+	ArchiveTarXz: tarballFunc(tarball.XZ),
+}
+
+func tarballFunc(c tarball.Compression) archiveFunc {
+	return func(s archiveSpec) error {
+		return tarball.ToFile(s.dir, s.file, c, s.modTime, s.log, tarball.WithPreservePaths(s.preservePaths))
+	}
+}
+
+func archiveFormatNames() []string {
+	var names []string
+	for n := range archiveFormats {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// createArchive writes the contents of the target dir to the archive
+// at ZipPath, in the configured format.
+func (b *core) createArchive(modTime time.Time) error {
+	c := b.config
+	return archiveFormats[c.Parameters.Archive()](archiveSpec{
+		dir:           c.Paths.TargetDir(),
+		file:          c.Paths.ZipPath,
+		modTime:       modTime,
+		preservePaths: c.Parameters.PreservePaths,
+		log:           b.Settings.Log,
+	})
+}
+
+// Archive returns the format of the archive to create. An empty
+// ArchiveFormat means zip.
+func (bp Parameters) Archive() string {
+	if bp.ArchiveFormat != "" {
+		return bp.ArchiveFormat
+	}
+	return ArchiveZip
+}
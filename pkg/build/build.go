@@ -5,14 +5,13 @@ package build
 
 import (
 	"fmt"
-	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
-	"github.com/hashicorp/actions-go-build/internal/zipper"
+	"github.com/hashicorp/actions-go-build/internal/ocilayout"
 	"github.com/hashicorp/composite-action-framework-go/pkg/fs"
-	"github.com/hashicorp/composite-action-framework-go/pkg/json"
 )
 
 // Build represents the build of a single binary.
@@ -27,6 +26,7 @@ type Build interface {
 	ChangeToVerificationRoot() error
 	IsVerification() bool
 	Dirs() TempDirs
+	Modules() *Modules
 }
 
 func New(name string, cfg Config, options ...Option) (Build, error) {
@@ -36,6 +36,8 @@ func New(name string, cfg Config, options ...Option) (Build, error) {
 type core struct {
 	Settings
 	config Config
+	// modules is set once modules have been prefetched.
+	modules *Modules
 }
 
 func errDirtyWorktree(dirtyFiles []string) error {
@@ -110,8 +112,8 @@ func (b *core) CachedResult() (Result, bool, error) {
 		return r, false, nil
 	}
 	b.Debug("Cache hit: %s", path)
-	r, err = json.ReadFile[Result](path)
-	r.loadedFromCache = true
+	r, err = ReadDocumentFile[Result](path)
+	r.FromCache = true
 	return r, err == nil, err
 }
 
@@ -121,7 +123,7 @@ func newStep(desc string, action StepFunc) Step {
 
 func (b *core) Steps() []Step {
 	var productRevisionTimestamp time.Time
-	return []Step{
+	steps := []Step{
 		newStep("validating inputs", func() error {
 			var err error
 			productRevisionTimestamp, err = b.Config().Product.RevisionTimestamp()
@@ -129,7 +131,13 @@ func (b *core) Steps() []Step {
 		}),
 
 		newStep("creating output directories", b.createDirectories),
+	}
+
+	if b.prefetchModules {
+		steps = append(steps, newStep("downloading Go modules", b.prefetchModuleCache))
+	}
 
+	steps = append(steps,
 		newStep("running build instructions", b.runInstructions),
 
 		newStep("asserting executable written", b.assertExecutableWritten),
@@ -138,10 +146,41 @@ func (b *core) Steps() []Step {
 			return fs.SetMtimes(b.Config().Paths.TargetDir(), productRevisionTimestamp)
 		}),
 
-		newStep(fmt.Sprintf("creating zip file %q", b.Config().Paths.ZipPath), func() error {
-			return zipper.ZipToFile(b.Config().Paths.TargetDir(), b.Config().Paths.ZipPath, b.Settings.Log)
+		newStep(fmt.Sprintf("creating %s file %q", b.Config().Parameters.Archive(), b.Config().Paths.ZipPath), func() error {
+			return b.createArchive(productRevisionTimestamp)
 		}),
+	)
+
+	if ociPath := b.Config().OCIPath(); ociPath != "" {
+		steps = append(steps, newStep(fmt.Sprintf("creating OCI image layout %q", ociPath), func() error {
+			return b.createImage(productRevisionTimestamp)
+		}))
+	}
+
+	return steps
+}
+
+func (b *core) createImage(created time.Time) error {
+	c := b.config
+	relBinPath := c.Product.ExecutableName
+	if c.Parameters.PreservePaths {
+		var err error
+		if relBinPath, err = filepath.Rel(c.Paths.TargetDir(), c.Paths.BinPath); err != nil {
+			return err
+		}
 	}
+	_, err := ocilayout.Build(c.Paths.TargetDir(), c.OCIPath(), ocilayout.Options{
+		BaseLayout:    c.OCIBaseLayoutPath(),
+		OS:            c.Parameters.OS,
+		Arch:          c.Parameters.Arch,
+		Created:       created,
+		Name:          c.Product.Name,
+		Tag:           c.Product.Version.Core,
+		Entrypoint:    "/" + filepath.ToSlash(relBinPath),
+		PreservePaths: c.Parameters.PreservePaths,
+		Log:           b.Settings.Log,
+	})
+	return err
 }
 
 func (b *core) createDirectories() error {
@@ -188,7 +227,11 @@ func (b *core) runInstructions() error {
 	c := b.newCommand(b.Settings.bash, path)
 	c.Env = b.Env()
 	b.Log("Build environment determined by config:\n%s", strings.Join(c.Env, "\n"))
-	c.Env = append(os.Environ(), c.Env...)
+	if env := b.moduleEnv(); len(env) != 0 {
+		b.Log("Module environment:\n%s", strings.Join(env, "\n"))
+		c.Env = append(c.Env, env...)
+	}
+	c.Env = append(b.hostEnv(), c.Env...)
 	b.Debug("Full build environment:\n%s", strings.Join(c.Env, "\n"))
 
 	return c.Run()
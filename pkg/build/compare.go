@@ -0,0 +1,231 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Impact says whether a difference between two builds is expected to make
+// them produce different artifacts.
+type Impact string
+
+const (
+	// ImpactOutput differences are in build inputs that are expected to
+	// change the artifacts produced.
+	ImpactOutput Impact = "affects output"
+	// ImpactNone differences are expected even between builds that
+	// reproduce each other, like paths and timings.
+	ImpactNone Impact = "not expected to affect output"
+	// ImpactArtifact differences are in the artifacts themselves.
+	ImpactArtifact Impact = "artifacts differ"
+)
+
+// Categories of Difference, in the order they're reported.
+const (
+	CategoryProduct    = "Product"
+	CategoryParameters = "Parameters"
+	CategoryModules    = "Modules"
+	CategoryTool       = "Tool"
+	CategoryEnv        = "Env"
+	CategoryPaths      = "Paths"
+	CategoryMeta       = "Meta"
+	CategoryArtifacts  = "Artifacts"
+)
+
+// Categories lists every category, in the order they're reported.
+var Categories = []string{
+	CategoryProduct, CategoryParameters, CategoryModules, CategoryTool,
+	CategoryEnv, CategoryPaths, CategoryMeta, CategoryArtifacts,
+}
+
+// Difference is a single field that's different between two builds.
+type Difference struct {
+	Category string
+	// Field is the path to the field, e.g. Product.Version.Full, or the
+	// name of the environment variable in CategoryEnv.
+	Field string
+	// A and B are the JSON encoded values of the field in each build.
+	A, B   string
+	Impact Impact
+}
+
+// Comparison lists the differences between two builds.
+type Comparison struct {
+	Differences []Difference
+}
+
+// AffectsOutput returns true if any difference is expected to affect, or
+// is in, the artifacts produced.
+func (c Comparison) AffectsOutput() bool {
+	for _, d := range c.Differences {
+		if d.Impact != ImpactNone {
+			return true
+		}
+	}
+	return false
+}
+
+// InCategory returns the differences in category.
+func (c Comparison) InCategory(category string) []Difference {
+	var out []Difference
+	for _, d := range c.Differences {
+		if d.Category == category {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// CompareConfigs compares the product, parameters, tool and paths of two
+// build configs.
+func CompareConfigs(a, b Config) Comparison {
+	var c Comparison
+	c.diff(CategoryProduct, "Product", a.Product, b.Product, always(ImpactOutput))
+	c.diff(CategoryParameters, "Parameters", a.Parameters, b.Parameters, always(ImpactOutput))
+	// Reproducible only changes what's expected of the build.
+	c.diff(CategoryParameters, "Reproducible", a.Reproducible, b.Reproducible, always(ImpactNone))
+	c.diff(CategoryTool, "Tool", a.Tool, b.Tool, always(ImpactNone))
+	c.diff(CategoryPaths, "Paths", a.Paths, b.Paths, always(ImpactNone))
+	return c
+}
+
+// Compare compares two build results: their configs, as well as their
+// environments, modules, metadata, and artifacts.
+func Compare(a, b Result) Comparison {
+	c := CompareConfigs(a.Config, b.Config)
+	c.diff(CategoryModules, "Modules", a.Modules, b.Modules, always(ImpactOutput))
+	c.diffEnv(a.Env, b.Env)
+	c.diff(CategoryMeta, "Meta", a.Meta, b.Meta, always(ImpactNone))
+	artifactImpact := func(field string) Impact {
+		// Artifacts are written to different places by different builds.
+		if strings.HasSuffix(field, ".OriginalPath") {
+			return ImpactNone
+		}
+		return ImpactArtifact
+	}
+	c.diff(CategoryArtifacts, "Executable", a.Executable, b.Executable, artifactImpact)
+	c.diff(CategoryArtifacts, "Zip", a.Zip, b.Zip, artifactImpact)
+	c.diff(CategoryArtifacts, "Image", a.Image, b.Image, artifactImpact)
+	c.diff(CategoryArtifacts, "Successful", a.Successful, b.Successful, always(ImpactArtifact))
+	sort.SliceStable(c.Differences, func(i, j int) bool {
+		return categoryIndex(c.Differences[i].Category) < categoryIndex(c.Differences[j].Category)
+	})
+	return c
+}
+
+func categoryIndex(category string) int {
+	for i, c := range Categories {
+		if c == category {
+			return i
+		}
+	}
+	return len(Categories)
+}
+
+func always(i Impact) func(string) Impact { return func(string) Impact { return i } }
+
+// diff records the differences between a and b, recursing into structs so
+// that each differing field is reported separately.
+func (c *Comparison) diff(category, field string, a, b any, impact func(field string) Impact) {
+	c.diffValues(category, field, reflect.ValueOf(a), reflect.ValueOf(b), impact)
+}
+
+func (c *Comparison) diffValues(category, field string, a, b reflect.Value, impact func(string) Impact) {
+	if a.Kind() == reflect.Pointer && !a.IsNil() && !b.IsNil() {
+		a, b = a.Elem(), b.Elem()
+	}
+	if a.Kind() == reflect.Struct {
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name := field
+			if !f.Anonymous {
+				name += "." + f.Name
+			}
+			c.diffValues(category, name, a.Field(i), b.Field(i), impact)
+		}
+		return
+	}
+	if reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return
+	}
+	c.Differences = append(c.Differences, Difference{
+		Category: category,
+		Field:    field,
+		A:        jsonString(a.Interface()),
+		B:        jsonString(b.Interface()),
+		Impact:   impact(field),
+	})
+}
+
+// diffEnv compares build environments by variable. Invariant variables are
+// expected to affect the output, and build-specific ones aren't.
+func (c *Comparison) diffEnv(a, b []string) {
+	aVars, bVars := envMap(a), envMap(b)
+	names := map[string]bool{}
+	for name := range aVars {
+		names[name] = true
+	}
+	for name := range bVars {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	buildSpecific := map[string]bool{}
+	for _, e := range BuildSpecificBuildEnvDefinitions() {
+		buildSpecific[e.Name] = true
+	}
+	for _, name := range sorted {
+		av, aok := aVars[name]
+		bv, bok := bVars[name]
+		if av == bv && aok == bok {
+			continue
+		}
+		impact := ImpactOutput
+		if buildSpecific[name] {
+			impact = ImpactNone
+		}
+		c.Differences = append(c.Differences, Difference{
+			Category: CategoryEnv,
+			Field:    name,
+			A:        envValue(av, aok),
+			B:        envValue(bv, bok),
+			Impact:   impact,
+		})
+	}
+}
+
+func envMap(env []string) map[string]string {
+	m := make(map[string]string, len(env))
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		m[name] = value
+	}
+	return m
+}
+
+func envValue(v string, ok bool) string {
+	if !ok {
+		return "null"
+	}
+	return jsonString(v)
+}
+
+func jsonString(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
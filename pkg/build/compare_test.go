@@ -0,0 +1,57 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/actions-go-build/pkg/crt"
+)
+
+func TestCompare(t *testing.T) {
+	a := standardResult()
+	a.Env = []string{"OS=linux", "BIN_PATH=/a/dist/lockbox"}
+	a.Executable = crt.File{Name: "lockbox", OriginalPath: "/a/dist/lockbox", Size: 10, SHA256Sum: "aaaa"}
+	b := a
+	b.Config = standardConfig("/b")
+	b.Config.Product.Version.Full = "1.2.4"
+	b.Config.Tool.Version = "2.0.0"
+	b.Env = []string{"OS=darwin", "BIN_PATH=/b/dist/lockbox"}
+	b.Executable = crt.File{Name: "lockbox", OriginalPath: "/b/dist/lockbox", Size: 10, SHA256Sum: "bbbb"}
+
+	got := Compare(a, b)
+	want := []Difference{
+		{CategoryProduct, "Product.Version.Full", `"1.2.3"`, `"1.2.4"`, ImpactOutput},
+		{CategoryTool, "Tool.Version", `""`, `"2.0.0"`, ImpactNone},
+		{CategoryEnv, "BIN_PATH", `"/a/dist/lockbox"`, `"/b/dist/lockbox"`, ImpactNone},
+		{CategoryEnv, "OS", `"linux"`, `"darwin"`, ImpactOutput},
+		{CategoryPaths, "Paths.WorkDir", `"/work"`, `"/b"`, ImpactNone},
+		{CategoryPaths, "Paths.BinPath", `"/work/dist/lockbox"`, `"/b/dist/lockbox"`, ImpactNone},
+		{CategoryPaths, "Paths.ZipPath", `"/work/out/lockbox_1.2.3_amd64.zip"`, `"/b/out/lockbox_1.2.3_amd64.zip"`, ImpactNone},
+		{CategoryPaths, "Paths.MetaDir", `"/work/meta"`, `"/b/meta"`, ImpactNone},
+		{CategoryArtifacts, "Executable.OriginalPath", `"/a/dist/lockbox"`, `"/b/dist/lockbox"`, ImpactNone},
+		{CategoryArtifacts, "Executable.SHA256Sum", `"aaaa"`, `"bbbb"`, ImpactArtifact},
+	}
+	if !reflect.DeepEqual(got.Differences, want) {
+		t.Errorf("got differences:\n%v\nwant:\n%v", got.Differences, want)
+	}
+	if !got.AffectsOutput() {
+		t.Errorf("got AffectsOutput() = false; want true")
+	}
+}
+
+func TestCompare_expectedOnly(t *testing.T) {
+	a := standardResult()
+	b := a
+	b.Config = standardConfig("/elsewhere")
+	b.Meta.Duration = "1m"
+	c := Compare(a, b)
+	if len(c.Differences) == 0 {
+		t.Fatal("got no differences")
+	}
+	if c.AffectsOutput() {
+		t.Errorf("got AffectsOutput() = true for differences %v; want false", c.Differences)
+	}
+}
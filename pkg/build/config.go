@@ -4,6 +4,10 @@
 package build
 
 import (
+	"path"
+	"path/filepath"
+	"strings"
+
 	"github.com/hashicorp/actions-go-build/pkg/crt"
 	"github.com/hashicorp/actions-go-build/pkg/digest"
 )
@@ -40,6 +44,9 @@ func NewConfig(product crt.Product, params Parameters, paths Paths, creator crt.
 
 // ConfigIDFunc can be overridden in tests to generate stable config IDs.
 var ConfigIDFunc = func(c Config) string {
+	// The source tree hash follows from Revision, and leaving it out keeps
+	// the IDs of configs recorded before it was added the same.
+	c.Product.SourceTreeHash = ""
 	return digest.ID(c)
 }
 
@@ -52,13 +59,29 @@ func (c Config) BuildResultCachePath(verification bool) string {
 	return newDirsFromConfig(c, verification).BuildResultCacheDir()
 }
 
-// ChangeRoot returns a copy of this Config with an updated build root.
+// ChangeRoot returns a copy of this Config with an updated build root. The
+// instructions are run in the product dir inside it.
 func (c Config) ChangeRoot(dir string) (Config, error) {
-	var err error
-	c.Paths, err = NewBuildPaths(dir, c.Product.ExecutableName, c.Parameters.ZipName)
+	workDir, err := c.Parameters.WorkDir(dir)
+	if err != nil {
+		return c, err
+	}
+	c.Paths, err = NewBuildPaths(workDir, c.Product.ExecutableName, c.Parameters.ZipName)
 	return c, err
 }
 
+// RootDir is the root of the source tree being built, which contains
+// Paths.WorkDir.
+func (c Config) RootDir() string {
+	root := c.Paths.WorkDir
+	if dir := path.Clean(c.Parameters.ProductDir); dir != "." {
+		for range strings.Split(dir, "/") {
+			root = filepath.Dir(root)
+		}
+	}
+	return root
+}
+
 func (c Config) ChangeToVerificationRoot() (Config, error) {
 	return c.ChangeRoot(c.VerificationRoot())
 }
@@ -74,3 +97,20 @@ func (c Config) VerificationRoot() string {
 func (c Config) RemotePrimaryRoot() string {
 	return newDirsFromConfig(c, false).RemoteBuildRoot()
 }
+
+// OCIPath returns the path to the OCI image layout this build creates,
+// or the empty string if it does not create one.
+func (c Config) OCIPath() string {
+	if c.Parameters.OCIBaseLayout == "" {
+		return ""
+	}
+	return filepath.Join(c.Paths.ZipDir(), c.Parameters.OCIName)
+}
+
+// OCIBaseLayoutPath returns the absolute path to the base image layout.
+func (c Config) OCIBaseLayoutPath() string {
+	if filepath.IsAbs(c.Parameters.OCIBaseLayout) {
+		return c.Parameters.OCIBaseLayout
+	}
+	return filepath.Join(c.Paths.WorkDir, c.Parameters.OCIBaseLayout)
+}
@@ -0,0 +1,19 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+import (
+	"testing"
+
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+func TestConfig_ID_sourceTreeHash(t *testing.T) {
+	c := standardConfig(tmp.Dir(t))
+	want := c.ID()
+	c.Product.SourceTreeHash = "0123456789abcdef0123456789abcdef01234567"
+	if got := c.ID(); got != want {
+		t.Errorf("recording the source tree hash changed the config ID from %s to %s", want, got)
+	}
+}
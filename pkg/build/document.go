@@ -0,0 +1,217 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"github.com/hashicorp/composite-action-framework-go/pkg/fs"
+	cjson "github.com/hashicorp/composite-action-framework-go/pkg/json"
+)
+
+// Kinds of document, as written in their Kind field.
+const (
+	KindConfig             = "BuildConfig"
+	KindResult             = "BuildResult"
+	KindVerificationResult = "VerificationResult"
+)
+
+// SchemaVersion is the version of the schema documents of every kind are
+// written with. Increment it and add a migration for each kind whenever a
+// change to Config, Result, VerificationResult, or anything they contain
+// would stop older documents being read.
+const SchemaVersion = 1
+
+// Document is a type that's written with its Kind and SchemaVersion, so that
+// it can be identified and upgraded when it's read.
+type Document interface {
+	Config | Result | VerificationResult
+}
+
+// header is written at the start of each document.
+type header struct {
+	Kind          string
+	SchemaVersion int
+}
+
+// KindOf returns the document kind of v, which may be a Document or a
+// pointer to one.
+func KindOf(v any) (string, bool) {
+	switch v.(type) {
+	case Config, *Config:
+		return KindConfig, true
+	case Result, *Result:
+		return KindResult, true
+	case VerificationResult, *VerificationResult:
+		return KindVerificationResult, true
+	}
+	return "", false
+}
+
+// MarshalDocument encodes v as indented JSON. If v is a Document, its Kind
+// and SchemaVersion are written first. If v is a slice, this applies to each
+// element.
+func MarshalDocument(v any) ([]byte, error) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+		elems := make([]json.RawMessage, rv.Len())
+		for i := range elems {
+			var err error
+			if elems[i], err = MarshalDocument(rv.Index(i).Interface()); err != nil {
+				return nil, err
+			}
+		}
+		v = elems
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if kind, ok := KindOf(v); ok && bytes.HasPrefix(data, []byte("{")) {
+		h, err := json.Marshal(header{Kind: kind, SchemaVersion: SchemaVersion})
+		if err != nil {
+			return nil, err
+		}
+		// Splice the header's fields into the start of the object.
+		if rest := data[1:]; !bytes.Equal(rest, []byte("}")) {
+			h = append(h[:len(h)-1], ',')
+			data = append(h, rest...)
+		} else {
+			data = h
+		}
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// WriteDocument writes v to w as described by MarshalDocument.
+func WriteDocument(w io.Writer, v any) error {
+	data, err := MarshalDocument(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// WriteDocumentFile writes v to the file at path as described by
+// MarshalDocument, creating any missing directories.
+func WriteDocumentFile(path string, v any) error {
+	f, err := fs.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteDocument(f, v); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// UpgradeDocument returns the kind of the JSON document in data, and the
+// document migrated to the current SchemaVersion, without its Kind and
+// SchemaVersion so that it can be decoded. Documents written before Kind
+// and SchemaVersion were added are identified by their fields.
+func UpgradeDocument(data []byte) (string, []byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Keep numbers exact while migrating.
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return "", nil, err
+	}
+	var h header
+	if v, ok := doc["Kind"]; ok {
+		if h.Kind, ok = v.(string); !ok {
+			return "", nil, fmt.Errorf("Kind must be a string, not %v", v)
+		}
+		delete(doc, "Kind")
+	}
+	if v, ok := doc["SchemaVersion"]; ok {
+		n, ok := v.(json.Number)
+		version, err := n.Int64()
+		if !ok || err != nil || version < 1 {
+			return "", nil, fmt.Errorf("SchemaVersion must be a positive integer, not %v", v)
+		}
+		h.SchemaVersion = int(version)
+		delete(doc, "SchemaVersion")
+	}
+	if h.Kind == "" {
+		if h.SchemaVersion != 0 {
+			return "", nil, fmt.Errorf("document has a SchemaVersion but no Kind")
+		}
+		var err error
+		if h.Kind, err = unversionedKind(doc); err != nil {
+			return "", nil, err
+		}
+	}
+	migrate, ok := migrations[h.Kind]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown document kind %q", h.Kind)
+	}
+	if h.SchemaVersion > SchemaVersion {
+		return "", nil, fmt.Errorf("%s has schema version %d, but this version of actions-go-build only reads "+
+			"up to version %d; it was probably written by a newer version", h.Kind, h.SchemaVersion, SchemaVersion)
+	}
+	for v := h.SchemaVersion; v < SchemaVersion; v++ {
+		if err := migrate[v](doc); err != nil {
+			return "", nil, fmt.Errorf("upgrading %s from schema version %d: %w", h.Kind, v, err)
+		}
+	}
+	body, err := json.Marshal(doc)
+	return h.Kind, body, err
+}
+
+// unversionedKind identifies a document written before Kind was added, from
+// fields only its kind has at the top level.
+func unversionedKind(doc map[string]any) (string, error) {
+	has := func(field string) bool { _, ok := doc[field]; return ok }
+	switch {
+	case has("Primary") && has("Verification"):
+		return KindVerificationResult, nil
+	case has("Config") && has("Meta"):
+		return KindResult, nil
+	case has("Product") && has("Parameters") && has("Paths"):
+		return KindConfig, nil
+	}
+	return "", fmt.Errorf("not a build config, build result, or verification result")
+}
+
+// ReadDocument decodes the JSON document in data as a T, upgrading it if it
+// was written with an older SchemaVersion. Unknown fields are an error.
+func ReadDocument[T Document](data []byte) (T, error) {
+	var t T
+	want, _ := KindOf(t)
+	kind, body, err := UpgradeDocument(data)
+	if err != nil {
+		return t, err
+	}
+	if kind != want {
+		return t, fmt.Errorf("document is a %s, not a %s", kind, want)
+	}
+	return cjson.ReadBytes[T](body)
+}
+
+// ReadDocumentFile reads the document in the file at path as described by
+// ReadDocument.
+func ReadDocumentFile[T Document](path string) (T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		var t T
+		return t, err
+	}
+	t, err := ReadDocument[T](data)
+	if err != nil {
+		return t, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return t, nil
+}
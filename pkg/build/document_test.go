@@ -0,0 +1,116 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func standardResult() Result {
+	return Result{
+		Config:     standardConfig("/work"),
+		Env:        []string{"OS=linux"},
+		Successful: true,
+	}
+}
+
+func TestReadDocument_roundTrip(t *testing.T) {
+	want := VerificationResult{Primary: &[]Result{standardResult()}[0], ReproducedCorrectly: true}
+	data, err := MarshalDocument(&want)
+	must(t, err)
+	if prefix := "{\n  \"Kind\": \"VerificationResult\",\n  \"SchemaVersion\": 1,\n  \"Primary\": {"; !bytes.HasPrefix(data, []byte(prefix)) {
+		t.Fatalf("got document:\n%s\nwant it to start with:\n%s", data, prefix)
+	}
+	got, err := ReadDocument[VerificationResult](data)
+	must(t, err)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v; want %#v", got, want)
+	}
+}
+
+func TestReadDocument_list(t *testing.T) {
+	data, err := MarshalDocument([]Result{standardResult(), standardResult()})
+	must(t, err)
+	var docs []json.RawMessage
+	must(t, json.Unmarshal(data, &docs))
+	for i, doc := range docs {
+		if _, err := ReadDocument[Result](doc); err != nil {
+			t.Errorf("document %d: %s", i, err)
+		}
+	}
+}
+
+// TestReadDocument_unversioned checks documents written before Kind and
+// SchemaVersion were added can still be read.
+func TestReadDocument_unversioned(t *testing.T) {
+	want := standardResult()
+	data, err := json.Marshal(want)
+	must(t, err)
+	got, err := ReadDocument[Result](data)
+	must(t, err)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v; want %#v", got, want)
+	}
+	c, err := json.Marshal(want.Config)
+	must(t, err)
+	if _, err := ReadDocument[Config](c); err != nil {
+		t.Errorf("reading unversioned config: %s", err)
+	}
+}
+
+func TestReadDocument_err(t *testing.T) {
+	cases := []struct {
+		desc, doc, want string
+	}{
+		{"wrong kind", `{"Kind": "BuildConfig", "SchemaVersion": 1}`, "document is a BuildConfig, not a BuildResult"},
+		{"unknown kind", `{"Kind": "Pizza", "SchemaVersion": 1}`, `unknown document kind "Pizza"`},
+		{"newer", `{"Kind": "BuildResult", "SchemaVersion": 99}`, "BuildResult has schema version 99, but this version of actions-go-build only reads up to version 1"},
+		{"bad version", `{"Kind": "BuildResult", "SchemaVersion": "1"}`, "SchemaVersion must be a positive integer, not 1"},
+		{"unversioned unknown", `{"Foo": "bar"}`, "not a build config, build result, or verification result"},
+		{"unknown field", `{"Kind": "BuildResult", "SchemaVersion": 1, "Foo": "bar"}`, `json: unknown field "Foo"`},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			_, err := ReadDocument[Result]([]byte(c.doc))
+			if err == nil || !strings.HasPrefix(err.Error(), c.want) {
+				t.Fatalf("got error %v; want error starting %q", err, c.want)
+			}
+		})
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	for kind, m := range migrations {
+		if len(m) != SchemaVersion {
+			t.Errorf("%s has %d migrations; want %d, one for each schema version", kind, len(m), SchemaVersion)
+		}
+	}
+}
+
+// TestSchemas checks the published schemas are up to date. Run with
+// UPDATE_TESTDATA=true to update them.
+func TestSchemas(t *testing.T) {
+	schemas, err := Schemas()
+	must(t, err)
+	dir := filepath.Join("..", "..", SchemaDir)
+	for name, want := range schemas {
+		path := filepath.Join(dir, name)
+		if os.Getenv("UPDATE_TESTDATA") == "true" {
+			must(t, os.MkdirAll(dir, 0o755))
+			must(t, os.WriteFile(path, want, 0o644))
+		}
+		got, err := os.ReadFile(path)
+		must(t, err)
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is out of date; run the tests with UPDATE_TESTDATA=true to update it", path)
+		}
+	}
+}
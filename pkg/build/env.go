@@ -5,6 +5,8 @@ package build
 
 import (
 	"fmt"
+	"os"
+	"strings"
 )
 
 // envVar represents a documented environment variable alongside
@@ -25,6 +27,39 @@ func (b *core) Env() []string {
 	return env
 }
 
+// alwaysAllowedEnv are inherited by the build instructions even when
+// Parameters.EnvAllowlist is set, because the go tool can't run without them.
+var alwaysAllowedEnv = []string{"PATH", "HOME", "TMPDIR"}
+
+// hostEnv returns the environment variables the build instructions inherit
+// from this process, limited to Parameters.EnvAllowlist if it's set.
+func (b *core) hostEnv() []string {
+	allow := b.config.Parameters.EnvAllowlist
+	if len(allow) == 0 {
+		return os.Environ()
+	}
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if envAllowed(name, allow) || envAllowed(name, alwaysAllowedEnv) {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+func envAllowed(name string, allow []string) bool {
+	for _, a := range allow {
+		if prefix, ok := strings.CutSuffix(a, "*"); ok && strings.HasPrefix(name, prefix) {
+			return true
+		}
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
 // InvariantBuildEnvDefinitions are environment variables that should be
 // set exactly the same for both primary build and all verification builds.
 // Changes to these variables could change the artifacts produced.
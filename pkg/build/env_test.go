@@ -0,0 +1,56 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+import (
+	"testing"
+
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+func TestEnvAllowed(t *testing.T) {
+	allow := []string{"GOPROXY", "AWS_*"}
+	cases := []struct {
+		name string
+		want bool
+	}{
+		{"GOPROXY", true},
+		{"GOPROXY2", false},
+		{"AWS_REGION", true},
+		{"AWS", false},
+		{"GITHUB_TOKEN", false},
+	}
+	for _, c := range cases {
+		if got := envAllowed(c.name, allow); got != c.want {
+			t.Errorf("envAllowed(%q) = %t; want %t", c.name, got, c.want)
+		}
+	}
+}
+
+func TestCore_moduleEnv_goFlags(t *testing.T) {
+	cases := []struct {
+		desc, goflags string
+		offline       bool
+		want          string
+	}{
+		{"unset", "", false, "GOFLAGS=-modcacherw"},
+		{"unset offline", "", true, "GOFLAGS=-mod=readonly -modcacherw"},
+		{"inherited", "-tags=netgo -trimpath", false, "GOFLAGS=-tags=netgo -trimpath -modcacherw"},
+		{"inherited offline", "-trimpath", true, "GOFLAGS=-trimpath -mod=readonly -modcacherw"},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			t.Setenv("GOFLAGS", c.goflags)
+			config := standardConfig(tmp.Dir(t))
+			config.Product.SourceHash = config.Product.Revision
+			b, err := New("test-build", config,
+				WithPrefetchModules(true), WithOfflineModules(c.offline))
+			must(t, err)
+			env := b.(*core).moduleEnv()
+			if got := env[len(env)-1]; got != c.want {
+				t.Errorf("got %q; want %q", got, c.want)
+			}
+		})
+	}
+}
@@ -11,7 +11,7 @@ import (
 )
 
 // LocalVerification is the local verification build. It is run inside a
-// temporary copy of the primary build's root directory.
+// temporary copy of the primary build's source tree, whose root is primaryRoot.
 type LocalVerification struct {
 	*core
 	primaryRoot string
@@ -44,7 +44,7 @@ func (lv *LocalVerification) Steps() []Step {
 		sleepTime = lv.startAfter.Sub(now)
 	}
 	pPath := lv.primaryRoot
-	vPath := lv.Config().Paths.WorkDir
+	vPath := lv.Config().RootDir()
 
 	pre := []Step{
 		newStep("ensuring new empty directory to run build in", func() error {
@@ -143,6 +143,7 @@ func (m *mockBuild) ChangeRoot(string) error         { return nil }
 func (m *mockBuild) ChangeToVerificationRoot() error { return nil }
 func (m *mockBuild) Kind() string                    { return "mock" }
 func (m *mockBuild) IsVerification() bool            { return false }
+func (m *mockBuild) Modules() *Modules               { return nil }
 func (m *mockBuild) Dirs() TempDirs {
 	return NewTempDirs("test", crt.Product{SourceHash: "deadbeef"}, Parameters{}, crt.Tool{})
 }
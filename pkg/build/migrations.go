@@ -0,0 +1,23 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+// migration upgrades a decoded document from one schema version to the next,
+// in place.
+type migration func(doc map[string]any) error
+
+// migrations holds the migrations for each kind of document. The migration at
+// index N upgrades a document from schema version N to N+1, so each list must
+// have SchemaVersion entries. Version 0 means a document written before
+// schema versions were added.
+var migrations = map[string][]migration{
+	KindConfig:             {fromUnversioned},
+	KindResult:             {fromUnversioned},
+	KindVerificationResult: {fromUnversioned},
+}
+
+// fromUnversioned upgrades to version 1, which only added Kind and
+// SchemaVersion. They're not part of the decoded document, so there's
+// nothing to do.
+func fromUnversioned(map[string]any) error { return nil }
@@ -0,0 +1,128 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/actions-go-build/pkg/digest"
+	"github.com/hashicorp/composite-action-framework-go/pkg/fs"
+)
+
+// Modules records the Go modules downloaded before running the build
+// instructions.
+type Modules struct {
+	// GoSumSHA256 is the SHA-256 of the go.sum file, or empty if there
+	// isn't one.
+	GoSumSHA256 string `json:",omitempty"`
+	// List contains each module downloaded, as path@version.
+	List []string
+	// Offline is true if the instructions were run with GOPROXY=off, so
+	// only the downloaded modules were available.
+	Offline bool `json:",omitempty"`
+}
+
+// modCacheDir is the GOMODCACHE used when prefetching modules.
+func (b *core) modCacheDir() string {
+	return b.Dirs().ModCacheDir()
+}
+
+// moduleEnv returns the environment variables which point the build
+// instructions at the prefetched module cache.
+func (b *core) moduleEnv() []string {
+	if !b.prefetchModules {
+		return nil
+	}
+	// The cache is read-only by default, which stops us cleaning it up.
+	flags := "-modcacherw"
+	env := []string{"GOMODCACHE=" + b.modCacheDir()}
+	if b.offlineModules {
+		flags = "-mod=readonly " + flags
+		env = append(env, "GOPROXY=off")
+	}
+	return append(env, b.goFlags(flags))
+}
+
+// goFlags returns a GOFLAGS assignment adding flags to any GOFLAGS the build
+// instructions would otherwise inherit, so we don't drop the user's own.
+func (b *core) goFlags(flags string) string {
+	for _, kv := range b.hostEnv() {
+		if v, ok := strings.CutPrefix(kv, "GOFLAGS="); ok && strings.TrimSpace(v) != "" {
+			flags = strings.TrimSpace(v) + " " + flags
+		}
+	}
+	return "GOFLAGS=" + flags
+}
+
+// prefetchModuleCache downloads all the modules needed to build the product
+// into the module cache, and records them.
+func (b *core) prefetchModuleCache() error {
+	dir := b.modCacheDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	b.Log("Downloading modules to %s", dir)
+	cmd := b.newCommand("go", "mod", "download", "-json")
+	cmd.Env = append(b.hostEnv(), "GOMODCACHE="+dir, b.goFlags("-modcacherw"))
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	runErr := cmd.Run()
+
+	// With -json, errors for individual modules are reported on stdout
+	// rather than stderr, so read it even if the command failed.
+	m := &Modules{Offline: b.offlineModules}
+	dec := json.NewDecoder(&stdout)
+	for {
+		var mod struct{ Path, Version, Error string }
+		if err := dec.Decode(&mod); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("reading go mod download output: %w", err)
+		}
+		if mod.Error != "" {
+			return fmt.Errorf("downloading %s@%s: %s", mod.Path, mod.Version, mod.Error)
+		}
+		m.List = append(m.List, mod.Path+"@"+mod.Version)
+	}
+	if runErr != nil {
+		return fmt.Errorf("go mod download: %w", runErr)
+	}
+	b.Debug("Downloaded %d modules", len(m.List))
+
+	goSum := filepath.Join(b.moduleDir(), "go.sum")
+	if _, err := os.Stat(goSum); err == nil {
+		if m.GoSumSHA256, err = digest.FileSHA256Hex(goSum); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	b.modules = m
+	return nil
+}
+
+// moduleDir returns the directory containing the go.mod for the work dir,
+// which may be above it when several products share a module.
+func (b *core) moduleDir() string {
+	root := b.config.RootDir()
+	dir := b.config.Paths.WorkDir
+	for dir != root && dir != filepath.Dir(dir) {
+		if ok, _ := fs.FileExists(filepath.Join(dir, "go.mod")); ok {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// Modules returns the modules downloaded by the prefetch step, or nil if it
+// hasn't run.
+func (b *core) Modules() *Modules { return b.modules }
@@ -6,6 +6,8 @@ package build
 import (
 	"fmt"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"runtime"
 	"strings"
 
@@ -25,8 +27,32 @@ type Parameters struct {
 	OS string `env:"OS"`
 	// Arch is the target Architecture for this build.
 	Arch string `env:"ARCH"`
-	// ZipName is the name of the zip file to create.
+	// ZipName is the name of the archive file to create. Despite the name,
+	// this is a tarball when ArchiveFormat is not zip.
 	ZipName string `env:"ZIP_NAME"`
+	// ArchiveFormat is the format of the archive to create: tar.gz or
+	// tar.xz. It's empty for zip, the default, so that configs which don't
+	// choose a format have the same ID as those written before it existed.
+	ArchiveFormat string `env:"ARCHIVE_FORMAT" json:",omitempty"`
+	// PreservePaths keeps the directory structure inside TARGET_DIR in the
+	// archive and any OCI image layer. By default the hierarchy is flattened.
+	PreservePaths bool `env:"PRESERVE_PATHS" json:",omitempty"`
+	// OCIBaseLayout is the path to a local OCI image layout containing the base
+	// image to build a container image on top of. Relative paths are resolved
+	// against the build root. If empty, no image is built.
+	OCIBaseLayout string `env:"OCI_BASE_LAYOUT" json:",omitempty"`
+	// OCIName is the name of the OCI image layout to create. Names ending in
+	// .tar produce a tarball, otherwise a directory is created.
+	OCIName string `env:"OCI_NAME" json:",omitempty"`
+	// EnvAllowlist, if set, limits the environment variables passed from the
+	// host to the build instructions to these names. Entries ending in * match
+	// any name with that prefix.
+	EnvAllowlist []string `env:"ENV_ALLOWLIST" json:",omitempty"`
+	// ProductDir is the slash-separated directory containing the product,
+	// relative to the root of the source tree, for repositories containing
+	// several products. The whole tree is still built from, but the
+	// instructions are run in this directory. Empty means the root.
+	ProductDir string `json:",omitempty"`
 }
 
 func (bp Parameters) Init(p crt.Product) (Parameters, error) {
@@ -34,7 +60,14 @@ func (bp Parameters) Init(p crt.Product) (Parameters, error) {
 }
 
 func (bp Parameters) trimSpace() Parameters {
-	trim(&bp.GoVersion, &bp.Instructions, &bp.OS, &bp.Arch, &bp.ZipName)
+	trim(&bp.GoVersion, &bp.Instructions, &bp.OS, &bp.Arch, &bp.ZipName, &bp.ArchiveFormat, &bp.OCIBaseLayout, &bp.OCIName, &bp.ProductDir)
+	var allow []string
+	for _, name := range bp.EnvAllowlist {
+		if name = strings.TrimSpace(name); name != "" {
+			allow = append(allow, name)
+		}
+	}
+	bp.EnvAllowlist = allow
 	return bp
 }
 
@@ -66,9 +99,24 @@ func (bp Parameters) setDefaults(p crt.Product) (Parameters, error) {
 	if bp.Arch == "" {
 		bp.Arch = runtime.GOARCH
 	}
+	if bp.ArchiveFormat == ArchiveZip {
+		bp.ArchiveFormat = ""
+	}
+	if _, ok := archiveFormats[bp.Archive()]; !ok {
+		return bp, fmt.Errorf("%q is not a valid archive format, must be one of %s", bp.ArchiveFormat, strings.Join(archiveFormatNames(), ", "))
+	}
+	if bp.ProductDir = path.Clean(bp.ProductDir); bp.ProductDir == "." {
+		bp.ProductDir = ""
+	}
+	if _, err := bp.WorkDir("/"); err != nil {
+		return bp, err
+	}
 	if bp.ZipName == "" {
 		bp.ZipName = bp.defaultZipName(p)
 	}
+	if bp.OCIName == "" && bp.OCIBaseLayout != "" {
+		bp.OCIName = bp.defaultOCIName(p)
+	}
 	if bp.Instructions == "" {
 		var err error
 		bp.Instructions, err = bp.defaultInstructions(p)
@@ -79,8 +127,22 @@ func (bp Parameters) setDefaults(p crt.Product) (Parameters, error) {
 	return bp, nil
 }
 
+// WorkDir returns the directory the instructions are run in, given the
+// absolute root of the source tree.
+func (bp Parameters) WorkDir(root string) (string, error) {
+	dir := path.Clean(bp.ProductDir)
+	if path.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, "../") {
+		return "", fmt.Errorf("product dir %q is not inside the source tree", bp.ProductDir)
+	}
+	return filepath.Join(root, filepath.FromSlash(dir)), nil
+}
+
 func (bp Parameters) defaultZipName(p crt.Product) string {
-	return fmt.Sprintf("%s_%s_%s_%s.zip", p.Name, p.Version.Full, bp.OS, bp.Arch)
+	return fmt.Sprintf("%s_%s_%s_%s.%s", p.Name, p.Version.Full, bp.OS, bp.Arch, bp.Archive())
+}
+
+func (bp Parameters) defaultOCIName(p crt.Product) string {
+	return fmt.Sprintf("%s_%s_%s_%s.oci.tar", p.Name, p.Version.Full, bp.OS, bp.Arch)
 }
 
 func (bp Parameters) defaultInstructions(p crt.Product) (string, error) {
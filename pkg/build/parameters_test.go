@@ -4,7 +4,10 @@
 package build
 
 import (
+	"path/filepath"
 	"testing"
+
+	"github.com/hashicorp/actions-go-build/pkg/crt"
 )
 
 func TestParseGoVersion(t *testing.T) {
@@ -38,3 +41,62 @@ func TestParseGoVersion(t *testing.T) {
 	}
 
 }
+
+func TestParameters_Init_archiveFormat(t *testing.T) {
+	p := crt.Product{Name: "lockbox", Version: crt.ProductVersion{Full: "1.2.3"}}
+	base := Parameters{GoVersion: "1.24", OS: "linux", Arch: "amd64", Instructions: "true"}
+
+	cases := []struct {
+		format, wantFormat, wantZipName string
+	}{
+		// Zip isn't recorded, so configs don't change ID.
+		{"", "", "lockbox_1.2.3_linux_amd64.zip"},
+		{"zip", "", "lockbox_1.2.3_linux_amd64.zip"},
+		{"tar.gz", "tar.gz", "lockbox_1.2.3_linux_amd64.tar.gz"},
+	}
+	for _, c := range cases {
+		t.Run(c.format, func(t *testing.T) {
+			in := base
+			in.ArchiveFormat = c.format
+			got, err := in.Init(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ArchiveFormat != c.wantFormat || got.ZipName != c.wantZipName {
+				t.Errorf("got format %q, zip name %q; want %q, %q", got.ArchiveFormat, got.ZipName, c.wantFormat, c.wantZipName)
+			}
+		})
+	}
+
+	if _, err := (Parameters{GoVersion: "1.24", ArchiveFormat: "rar"}).Init(p); err == nil {
+		t.Error("got nil error for an invalid archive format")
+	}
+}
+
+func TestParameters_WorkDir(t *testing.T) {
+	cases := []struct {
+		dir, want, wantErr string
+	}{
+		{"", "/src", ""},
+		{"cmd/lockbox", "/src/cmd/lockbox", ""},
+		{"cmd/../lockbox", "/src/lockbox", ""},
+		{"..", "", `product dir ".." is not inside the source tree`},
+		{"../lockbox", "", `product dir "../lockbox" is not inside the source tree`},
+		{"/cmd/lockbox", "", `product dir "/cmd/lockbox" is not inside the source tree`},
+	}
+	for _, c := range cases {
+		got, err := Parameters{ProductDir: c.dir}.WorkDir("/src")
+		if c.wantErr != "" {
+			if err == nil || err.Error() != c.wantErr {
+				t.Errorf("WorkDir with %q: got error %v; want %q", c.dir, err, c.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != filepath.FromSlash(c.want) {
+			t.Errorf("WorkDir with %q: got %q; want %q", c.dir, got, c.want)
+		}
+	}
+}
@@ -5,48 +5,204 @@ package build
 
 import (
 	"fmt"
-	"io"
-	"net/http"
-	"os"
-	"path"
 	"path/filepath"
 	"strings"
 
+	"github.com/hashicorp/actions-go-build/internal/fetch"
+	"github.com/hashicorp/actions-go-build/internal/gittree"
+	"github.com/hashicorp/actions-go-build/internal/source"
 	"github.com/hashicorp/actions-go-build/internal/unzipper"
+	"github.com/hashicorp/actions-go-build/pkg/crt"
 	"github.com/hashicorp/composite-action-framework-go/pkg/fs"
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
 )
 
 // RemoteBuild is a build where the source code is hosted remotely.
 // This can be a "primary" or verification build.
 type RemoteBuild struct {
 	*core
+	// sourceURL is the URL of the source archive or, in SourceGit mode,
+	// of the repository to clone.
 	sourceURL string
-	cacheID   string
+	// innerDir is the directory inside the source download dir containing
+	// the source code. If empty, it's the archive's only top-level directory.
+	innerDir string
+	// sendToken is true if the GitHub token may be sent to the source host.
+	sendToken bool
+	// clonedTreeHash is the tree hash git checked out, in SourceGit mode.
+	clonedTreeHash string
+	// module and wantModuleSum are the module version to download and its
+	// expected zip hash, and proxy is where it's downloaded from, in
+	// SourceModule mode.
+	module        module.Version
+	wantModuleSum string
+	proxy         source.ModuleProxy
+	cacheID       string
 }
 
+// Source modes for remote builds.
+const (
+	// SourceArchive downloads a zip archive of the source code from the
+	// repository's forge.
+	SourceArchive = "archive"
+	// SourceGit clones the repository, including submodules.
+	SourceGit = "git"
+	// SourceModule downloads the module zip from a Go module proxy.
+	SourceModule = "module"
+)
+
 func NewRemoteBuild(c Config, options ...Option) (Build, error) {
 	if c.Product.IsDirty() {
 		return nil, fmt.Errorf("cannot verify a dirty build remotely")
 	}
 
-	parts := strings.Split(c.Product.Module, "/")
-	if len(parts) < 3 {
-		return nil, fmt.Errorf("module %q not supported, must be in the format %q", c.Product.Module, "github.com/<user>/<repo>...")
-	}
-	user, repo, revision := parts[1], parts[2], c.Product.Revision
-
-	sourceURL := fmt.Sprintf("https://github.com/%s/%s/archive/%s.zip", user, repo, revision)
 	core, err := newCore("remote build", c, options...)
 	if err != nil {
 		return nil, err
 	}
+	rb := &RemoteBuild{core: core}
+	switch core.sourceMode {
+	case SourceArchive:
+		err = rb.initArchiveSource()
+	case SourceGit:
+		err = rb.initGitSource()
+	case SourceModule:
+		err = rb.initModuleSource()
+	default:
+		err = fmt.Errorf("unknown source mode %q; want %q, %q, or %q", core.sourceMode, SourceArchive, SourceGit, SourceModule)
+	}
+	if err != nil {
+		return nil, err
+	}
 	if err := core.UpdateBuildRoot(); err != nil {
 		return nil, err
 	}
-	return &RemoteBuild{
-		core:      core,
-		sourceURL: sourceURL,
-	}, nil
+	return rb, nil
+}
+
+func (rb *RemoteBuild) initArchiveSource() error {
+	c := rb.Config()
+	provider, repo, err := rb.sourceProviders.For(sourceRepository(c.Product))
+	if err != nil {
+		return err
+	}
+	if rb.sourceURL, err = provider.ArchiveURL(repo, c.Product.Revision); err != nil {
+		return err
+	}
+	if rb.innerDir, err = provider.InnerDir(repo, c.Product.Revision); err != nil {
+		return err
+	}
+	// The token is only sent if the archive is downloaded from GitHub
+	// itself, not e.g. from a mirror configured for a GitHub repository.
+	gh, isGitHub := provider.(source.GitHub)
+	if !isGitHub || !rb.sendsTokenTo(rb.sourceURL) {
+		return nil
+	}
+	rb.sendToken = true
+	if rb.GitHubToken().Value != "" {
+		// Archive URLs ignore tokens, so private repositories can only be
+		// downloaded from the API.
+		rb.sourceURL = gh.APIArchiveURL(repo, c.Product.Revision)
+		rb.innerDir = ""
+		rb.sendToken = rb.sendsTokenTo(rb.sourceURL)
+	}
+	return nil
+}
+
+func (rb *RemoteBuild) initGitSource() error {
+	rb.sourceURL = rb.cloneURL
+	if rb.sourceURL == "" {
+		rb.sourceURL = cloneURL(rb.Config().Product)
+	}
+	rb.innerDir = "source"
+	rb.sendToken = rb.sendsTokenTo(rb.sourceURL)
+	return nil
+}
+
+func (rb *RemoteBuild) initModuleSource() error {
+	p := rb.Config().Product
+	rb.module = module.Version{Path: p.Module, Version: "v" + strings.TrimPrefix(p.Version.Full, "v")}
+	if rb.moduleSum == "" {
+		return fmt.Errorf("source mode %q needs the expected hash of %s, e.g. from go.sum", SourceModule, rb.module)
+	}
+	var err error
+	if rb.wantModuleSum, err = source.ParseModuleSum(rb.moduleSum, rb.module); err != nil {
+		return err
+	}
+	if rb.proxy, err = source.ProxyFromGOPROXY(rb.goproxy); err != nil {
+		return err
+	}
+	if rb.sourceURL, err = rb.proxy.ZipURL(rb.module); err != nil {
+		return err
+	}
+	rb.innerDir = "source"
+	return nil
+}
+
+// cloneURL returns Product.Repository if it's already a URL or local path,
+// otherwise an https URL for it.
+func cloneURL(p crt.Product) string {
+	if strings.Contains(p.Repository, "://") || filepath.IsAbs(p.Repository) {
+		return p.Repository
+	}
+	return "https://" + sourceRepository(p)
+}
+
+// verifySourceTreeHash checks that the downloaded source code is exactly the
+// tree committed at the product revision, so that a tampered or regenerated
+// archive can't be built in its place.
+func (rb *RemoteBuild) verifySourceTreeHash() error {
+	c := rb.Config()
+	if rb.sourceMode == SourceModule {
+		// Module zips omit nested modules, vendor directories of dependencies
+		// and some other files, so they never match the git tree. Their hash
+		// has already been checked against the expected module sum instead.
+		rb.Log("Not verifying source tree hash of module zip; its module sum was verified instead.")
+		return nil
+	}
+	want := c.Product.SourceTreeHash
+	if want == "" {
+		rb.Loud("WARNING: No source tree hash recorded for revision %s, so the downloaded source "+
+			"can't be checked against it. Record one by rebuilding with this version of actions-go-build.", c.Product.Revision)
+		return nil
+	}
+	if got := rb.clonedTreeHash; got != "" {
+		// Git has already checked the cloned objects' hashes.
+		if got != want {
+			return fmt.Errorf("repository at %s has tree hash %s for revision %s; want %s",
+				rb.sourceURL, got, c.Product.Revision, want)
+		}
+		rb.Debug("Cloned source tree hash matches revision %s: %s", c.Product.Revision, got)
+		return nil
+	}
+	got, err := gittree.Hash(c.RootDir())
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("downloaded source has tree hash %s, but revision %s has tree hash %s; "+
+			"the archive at %s may have been tampered with, or altered by export-ignore or "+
+			"export-subst attributes, or the repository may contain submodules (try source mode %q)",
+			got, c.Product.Revision, want, rb.sourceURL, SourceGit)
+	}
+	rb.Debug("Downloaded source tree hash matches revision %s: %s", c.Product.Revision, got)
+	return nil
+}
+
+// sourceRepository returns the host-qualified repository to download source
+// code from. Product.Repository often lacks a host, e.g. when it comes from
+// GITHUB_REPOSITORY, in which case the repository is taken from the first
+// three segments of the Go module path instead.
+func sourceRepository(p crt.Product) string {
+	if first, _, _ := strings.Cut(p.Repository, "/"); strings.ContainsAny(first, ".:") {
+		return p.Repository
+	}
+	parts := strings.Split(p.Module, "/")
+	if len(parts) > 3 {
+		parts = parts[:3]
+	}
+	return strings.Join(parts, "/")
 }
 
 func (rb *RemoteBuild) Steps() []Step {
@@ -64,47 +220,86 @@ func (rb *RemoteBuild) Steps() []Step {
 			sourceDLDir = rb.Dirs().SourceDownloadDir()
 			return fs.MkdirEmpty(sourceDLDir)
 		}),
-		newStep(fmt.Sprintf("get %s", rb.sourceURL), func() error {
-			c := rb.Config()
-			sourceArchiveName := fmt.Sprintf("%s-%s.zip", c.Product.Name, c.Product.Revision)
-			sourceArchivePath = filepath.Join(sourceDLDir, sourceArchiveName)
-			destFile, err := os.Create(sourceArchivePath)
-			if err != nil {
-				return err
-			}
-			var closeErr error
-			defer func() { closeErr = destFile.Close() }()
-			response, err := http.Get(rb.sourceURL)
-			if err != nil {
-				return err
-			}
-			if response.StatusCode != http.StatusOK {
-				return fmt.Errorf("%s", response.Status)
-			}
-			var bodyCloseErr error
-			defer func() { bodyCloseErr = response.Body.Close() }()
-			if _, err := io.Copy(destFile, response.Body); err != nil {
-				return err
-			}
-			if closeErr != nil {
-				return closeErr
+	}
+
+	switch rb.sourceMode {
+	case SourceGit:
+		pre = append(pre, newStep(fmt.Sprintf("clone %s at %s", rb.sourceURL, rb.Config().Product.Revision), func() error {
+			var token fetch.Token
+			if rb.sendToken {
+				token = rb.GitHubToken()
+				rb.Debug("Cloning source using %s", token)
 			}
-			return bodyCloseErr
-		}),
-		newStep("extract source code to temporary directory", func() error {
-			// Extract the downloaded zip file directly in the same dir as the zip.
-			// These zips contain a directory that contains all the code, so we'll
-			// use that directory as the build root.
-			return unzipper.New(rb.Debug).Unzip(sourceArchivePath, sourceDLDir)
-		}),
+			var err error
+			rb.clonedTreeHash, err = source.Cloner{
+				Context: rb.context,
+				Token:   token,
+				Log:     rb.Debug,
+				Stderr:  rb.stderr,
+			}.Clone(rb.sourceURL, rb.Config().Product.Revision, filepath.Join(sourceDLDir, rb.innerDir))
+			return err
+		}))
+	case SourceModule:
+		pre = append(pre,
+			newStep(fmt.Sprintf("get %s", rb.sourceURL), func() error {
+				sourceArchivePath = filepath.Join(sourceDLDir, "module.zip")
+				return rb.proxy.Download(rb.context, rb.fetchClient, rb.module, sourceArchivePath)
+			}),
+			newStep("verify module zip hash", func() error {
+				if err := source.CheckZipSum(sourceArchivePath, rb.wantModuleSum); err != nil {
+					return fmt.Errorf("%s from %s: %w", rb.module, rb.sourceURL, err)
+				}
+				rb.Debug("Module zip hash matches %s", rb.wantModuleSum)
+				return nil
+			}),
+			newStep("extract module to temporary directory", func() error {
+				// Unzip checks the zip is a valid module zip for exactly this
+				// module version, and strips the module@version/ prefix.
+				return modzip.Unzip(filepath.Join(sourceDLDir, rb.innerDir), rb.module, sourceArchivePath)
+			}),
+		)
+	default:
+		pre = append(pre,
+			newStep(fmt.Sprintf("get %s", rb.sourceURL), func() error {
+				c := rb.Config()
+				sourceArchiveName := fmt.Sprintf("%s-%s.zip", c.Product.Name, c.Product.Revision)
+				sourceArchivePath = filepath.Join(sourceDLDir, sourceArchiveName)
+				var token fetch.Token
+				if rb.sendToken {
+					token = rb.GitHubToken()
+					rb.Debug("Downloading source using %s", token)
+				}
+				return rb.fetchClient.Download(rb.context, rb.sourceURL, token, sourceArchivePath)
+			}),
+			newStep("extract source code to temporary directory", func() error {
+				// Extract the downloaded zip file directly in the same dir as the zip.
+				// These zips contain a directory that contains all the code, so we'll
+				// use that directory as the build root.
+				// Source archives may legitimately contain symlinks; the unzipper
+				// still refuses any that point outside the source tree.
+				opts := []unzipper.Option{unzipper.WithSymlinks(true)}
+				if l := rb.Settings.unzipLimits; l != nil {
+					opts = append(opts, unzipper.WithLimits(*l))
+				}
+				return unzipper.New(rb.Debug, opts...).Unzip(sourceArchivePath, sourceDLDir)
+			}),
+		)
+	}
+
+	pre = append(pre,
 		newStep("move source code to build root", func() error {
-			c := rb.Config()
-			// This innerDirName is GitHub-specific.
-			innerDirName := fmt.Sprintf("%s-%s", path.Base(c.Product.Repository), c.Product.Revision)
-			sourcePath := filepath.Join(sourceDLDir, innerDirName)
-			return fs.Move(sourcePath, rb.Config().Paths.WorkDir)
+			innerDir := rb.innerDir
+			if innerDir == "" {
+				var err error
+				if innerDir, err = source.FindInnerDir(sourceDLDir); err != nil {
+					return err
+				}
+			}
+			sourcePath := filepath.Join(sourceDLDir, innerDir)
+			return fs.Move(sourcePath, rb.Config().RootDir())
 		}),
-	}
+		newStep("verify source tree hash", rb.verifySourceTreeHash),
+	)
 
 	return append(pre, rb.core.Steps()...)
 }
@@ -0,0 +1,450 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/actions-go-build/internal/gittree"
+	"github.com/hashicorp/actions-go-build/internal/source"
+	"github.com/hashicorp/actions-go-build/internal/zipper"
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+	modzip "golang.org/x/mod/zip"
+)
+
+func TestRemoteBuild_Run_ok(t *testing.T) {
+	cases := []struct {
+		desc, repo string
+		provider   func(baseURL string) source.Provider
+		// urlPath and innerDir are what the forge serves.
+		urlPath, innerDir string
+	}{
+		{
+			"github",
+			"github.example/dadgarcorp/lockbox",
+			func(u string) source.Provider { return source.GitHub{BaseURL: u} },
+			"/dadgarcorp/lockbox/archive/cabba9e.zip",
+			"lockbox-cabba9e",
+		},
+		{
+			"gitlab",
+			"gitlab.example/dadgarcorp/tools/lockbox",
+			func(u string) source.Provider { return source.GitLab{BaseURL: u} },
+			"/dadgarcorp/tools/lockbox/-/archive/cabba9e/lockbox-cabba9e.zip",
+			"lockbox-cabba9e",
+		},
+		{
+			"gitea",
+			"gitea.example/dadgarcorp/lockbox",
+			func(u string) source.Provider { return source.Gitea{BaseURL: u} },
+			"/dadgarcorp/lockbox/archive/cabba9e.zip",
+			"lockbox",
+		},
+		{
+			"template with inner dir",
+			"mirror.example/dadgarcorp/lockbox",
+			func(u string) source.Provider {
+				return source.Template{
+					URLTemplate:      u + "/mirror/{{.Owner}}/{{.Name}}@{{.Revision}}.zip",
+					InnerDirTemplate: "src-{{.Revision}}",
+				}
+			},
+			"/mirror/dadgarcorp/lockbox@cabba9e.zip",
+			"src-cabba9e",
+		},
+		{
+			"template without inner dir",
+			"other.example/dadgarcorp/lockbox",
+			func(u string) source.Provider {
+				return source.Template{URLTemplate: u + "/{{.Path}}.zip"}
+			},
+			"/dadgarcorp/lockbox.zip",
+			"anything",
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			// Remote builds are rooted in the system temp dir.
+			t.Setenv("TMPDIR", tmp.Dir(t))
+			archive, treeHash := createSourceArchive(t, c.innerDir)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != c.urlPath {
+					t.Errorf("got request for %q; want %q", r.URL.Path, c.urlPath)
+					http.NotFound(w, r)
+					return
+				}
+				http.ServeFile(w, r, archive)
+			}))
+			defer srv.Close()
+
+			dir := tmp.Dir(t)
+			config := standardConfig(dir)
+			config.Product.Repository = c.repo
+			config.Product.SourceHash = config.Product.Revision
+			config.Product.SourceTreeHash = treeHash
+			config.Product.ExecutableName = "lockbox"
+			config.Parameters.ZipName = "lockbox_1.2.3_linux_amd64.zip"
+			rb, err := NewRemoteBuild(config, WithSourceProvider(host(c.repo), c.provider(srv.URL)), WithForceRebuild(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := NewRunner(rb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Run().Error(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRemoteBuild_Run_err_treeHash(t *testing.T) {
+	t.Setenv("TMPDIR", tmp.Dir(t))
+	archive, _ := createSourceArchive(t, "lockbox-cabba9e")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, archive)
+	}))
+	defer srv.Close()
+
+	config := standardConfig(tmp.Dir(t))
+	config.Product.Repository = "github.example/dadgarcorp/lockbox"
+	config.Product.SourceHash = config.Product.Revision
+	config.Product.SourceTreeHash = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+	config.Product.ExecutableName = "lockbox"
+	rb, err := NewRemoteBuild(config, WithSourceProvider("github.example", source.GitHub{BaseURL: srv.URL}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRunner(rb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Run().Error()
+	want := "but revision cabba9e has tree hash 4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("got error %v; want error containing %q", err, want)
+	}
+}
+
+func TestRemoteBuild_Run_productDir(t *testing.T) {
+	t.Setenv("TMPDIR", tmp.Dir(t))
+	// The product is in a subdirectory, using the go.mod at the root.
+	archive, treeHash := createSourceArchiveFiles(t, "lockbox-cabba9e", map[string]string{
+		"cmd/lockbox/main.go": mainDotGo,
+		"go.mod":              goDotMod,
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, archive)
+	}))
+	defer srv.Close()
+
+	config := standardConfig(tmp.Dir(t))
+	config.Product.Repository = "github.example/dadgarcorp/lockbox"
+	config.Product.SourceHash = config.Product.Revision
+	config.Product.SourceTreeHash = treeHash
+	config.Product.ExecutableName = "lockbox"
+	config.Parameters.ProductDir = "cmd/lockbox"
+	rb, err := NewRemoteBuild(config, WithSourceProvider("github.example", source.GitHub{BaseURL: srv.URL}), WithForceRebuild(true))
+	must(t, err)
+	r, err := NewRunner(rb)
+	must(t, err)
+	must(t, r.Run().Error())
+
+	c := rb.Config()
+	if got, want := c.Paths.WorkDir, filepath.Join(c.RootDir(), "cmd", "lockbox"); got != want {
+		t.Errorf("got work dir %q; want %q", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(c.RootDir(), "go.mod")); err != nil {
+		t.Errorf("source root doesn't contain go.mod: %s", err)
+	}
+}
+
+func TestRemoteBuild_Run_git(t *testing.T) {
+	t.Setenv("TMPDIR", tmp.Dir(t))
+	bare, rev, treeHash := createBareRepoWithSubmodule(t)
+
+	for _, c := range []struct {
+		desc, repository, cloneURL string
+		// allowFile is whether the user allows local submodules, which git
+		// refuses by default.
+		allowFile bool
+		wantErr   string
+	}{
+		{"bare repo path", bare, "", true, ""},
+		{"file URL override", "github.com/dadgarcorp/lockbox", "file://" + bare, true, ""},
+		{"local submodule refused", bare, "", false, "git submodule"},
+	} {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			if c.allowFile {
+				t.Setenv("GIT_CONFIG_COUNT", "1")
+				t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+				t.Setenv("GIT_CONFIG_VALUE_0", "always")
+			}
+			config := standardConfig(tmp.Dir(t))
+			config.Product.Repository = c.repository
+			config.Product.Revision = rev
+			config.Product.SourceHash = rev
+			config.Product.SourceTreeHash = treeHash
+			config.Product.ExecutableName = "lockbox"
+			// The build fails unless the submodule was checked out.
+			config.Parameters.Instructions = "test -f lib/data.txt && go build -o $BIN_PATH"
+			rb, err := NewRemoteBuild(config, WithSourceMode(SourceGit), WithCloneURL(c.cloneURL), WithForceRebuild(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := NewRunner(rb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = r.Run().Error()
+			if c.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Fatalf("got error %v; want error containing %q", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestRemoteBuild_Run_module(t *testing.T) {
+	t.Setenv("TMPDIR", tmp.Dir(t))
+	m := module.Version{Path: "github.com/dadgarcorp/lockbox", Version: "v1.2.3"}
+	proxyDir, sum, _ := createModuleProxy(t, m, map[string]string{"main.go": mainDotGo, "go.mod": goDotMod})
+	srv := httptest.NewServer(http.FileServer(http.Dir(proxyDir)))
+	defer srv.Close()
+	goSum := filepath.Join(tmp.Dir(t), "go.sum")
+	must(t, os.WriteFile(goSum, []byte(fmt.Sprintf("%s %s %s\n", m.Path, m.Version, sum)), 0o644))
+
+	for _, c := range []struct {
+		desc, goproxy, moduleSum, wantErr string
+	}{
+		{"file proxy", "file://" + proxyDir, sum, ""},
+		{"http proxy", "direct," + srv.URL + ",off", goSum, ""},
+		{"wrong sum", srv.URL, "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", "module zip has hash " + sum},
+	} {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			config := standardConfig(tmp.Dir(t))
+			config.Product.Module = m.Path
+			config.Product.SourceHash = config.Product.Revision
+			config.Product.ExecutableName = "lockbox"
+			rb, err := NewRemoteBuild(config, WithSourceMode(SourceModule),
+				WithGOPROXY(c.goproxy), WithModuleSum(c.moduleSum), WithForceRebuild(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := NewRunner(rb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = r.Run().Error()
+			if c.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Fatalf("got error %v; want error containing %q", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRemoteBuild_err_noModuleSum(t *testing.T) {
+	config := standardConfig(tmp.Dir(t))
+	config.Product.Module = "github.com/dadgarcorp/lockbox"
+	config.Product.SourceHash = config.Product.Revision
+	_, err := NewRemoteBuild(config, WithSourceMode(SourceModule))
+	want := `source mode "module" needs the expected hash of github.com/dadgarcorp/lockbox@v1.2.3, e.g. from go.sum`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v; want %q", err, want)
+	}
+}
+
+func TestNewRemoteBuild_err_unknownHost(t *testing.T) {
+	config := standardConfig(tmp.Dir(t))
+	config.Product.Repository = "forge.example/dadgarcorp/lockbox"
+	config.Product.SourceHash = config.Product.Revision
+	_, err := NewRemoteBuild(config)
+	want := `no source provider for host "forge.example" (known hosts: codeberg.org, gitea.com, github.com, gitlab.com)`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got error %v; want %q", err, want)
+	}
+}
+
+// createBareRepoWithSubmodule creates a bare repository containing a
+// buildable Go program and a submodule, and returns its path, along with the
+// revision and tree hash of HEAD.
+func createBareRepoWithSubmodule(t *testing.T) (path, rev, treeHash string) {
+	t.Helper()
+	lib := tmp.Dir(t)
+	must(t, os.WriteFile(filepath.Join(lib, "data.txt"), []byte("data"), 0o644))
+	runGit(t, lib, "init", "-q")
+	runGit(t, lib, "add", ".")
+	runGit(t, lib, "commit", "-q", "-m", "lib")
+
+	work := tmp.Dir(t)
+	must(t, os.WriteFile(filepath.Join(work, "main.go"), []byte(mainDotGo), 0o644))
+	must(t, os.WriteFile(filepath.Join(work, "go.mod"), []byte(goDotMod), 0o644))
+	runGit(t, work, "init", "-q")
+	runGit(t, work, "-c", "protocol.file.allow=always", "submodule", "add", "-q", lib, "lib")
+	runGit(t, work, "add", ".")
+	runGit(t, work, "commit", "-q", "-m", "initial commit")
+
+	path = filepath.Join(tmp.Dir(t), "lockbox.git")
+	runGit(t, work, "clone", "-q", "--bare", work, path)
+	return path, runGit(t, work, "rev-parse", "HEAD"), runGit(t, work, "rev-parse", "HEAD^{tree}")
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@test.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %s: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func host(repo string) string {
+	r, err := source.ParseRepo(repo)
+	if err != nil {
+		panic(err)
+	}
+	return r.Host
+}
+
+// createModuleProxy creates a directory laid out like a module proxy,
+// serving module m with the given files. It returns the directory and the
+// go.sum lines for m.
+func createModuleProxy(t *testing.T, m module.Version, files map[string]string) (dir string, sum, goModSum string) {
+	t.Helper()
+	src := tmp.Dir(t)
+	for name, contents := range files {
+		must(t, os.WriteFile(filepath.Join(src, name), []byte(contents), 0o644))
+	}
+	dir = tmp.Dir(t)
+	versionDir := filepath.Join(dir, m.Path, "@v")
+	must(t, os.MkdirAll(versionDir, 0o755))
+	must(t, os.WriteFile(filepath.Join(versionDir, m.Version+".mod"), []byte(files["go.mod"]), 0o644))
+	must(t, os.WriteFile(filepath.Join(versionDir, m.Version+".info"), []byte(`{"Version":"`+m.Version+`"}`), 0o644))
+	zipFile := filepath.Join(versionDir, m.Version+".zip")
+	f, err := os.Create(zipFile)
+	must(t, err)
+	must(t, modzip.CreateFromDir(f, m, src))
+	must(t, f.Close())
+	sum, err = dirhash.HashZip(zipFile, dirhash.Hash1)
+	must(t, err)
+	goModSum, err = dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(files["go.mod"])), nil
+	})
+	must(t, err)
+	return dir, sum, goModSum
+}
+
+// createSourceArchive creates a zip containing a buildable Go program
+// inside innerDir, like a forge's source archive. It also returns the
+// program's tree hash.
+func createSourceArchive(t *testing.T, innerDir string) (string, string) {
+	t.Helper()
+	return createSourceArchiveFiles(t, innerDir, map[string]string{"main.go": mainDotGo, "go.mod": goDotMod})
+}
+
+func createSourceArchiveFiles(t *testing.T, innerDir string, files map[string]string) (string, string) {
+	t.Helper()
+	root := tmp.Dir(t)
+	for name, contents := range files {
+		path := filepath.Join(root, innerDir, name)
+		must(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		must(t, os.WriteFile(path, []byte(contents), 0o644))
+	}
+	archive := filepath.Join(tmp.Dir(t), "source.zip")
+	must(t, zipper.ZipToFile(root, archive, t.Logf, zipper.WithPreservePaths(true)))
+	treeHash, err := gittree.Hash(filepath.Join(root, innerDir))
+	must(t, err)
+	return archive, treeHash
+}
+
+func TestNewRemoteBuild_sendToken(t *testing.T) {
+	mirror := source.Template{URLTemplate: "https://mirror.example/{{.Path}}/{{.Revision}}.zip"}
+	cases := []struct {
+		desc string
+		opts []Option
+		want bool
+	}{
+		{"github", nil, true},
+		{"github mirror", []Option{WithSourceProvider("github.com", mirror)}, false},
+		{"git clone from github", []Option{WithSourceMode(SourceGit)}, true},
+		{"git clone from mirror", []Option{WithSourceMode(SourceGit), WithCloneURL("https://mirror.example/lockbox.git")}, false},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			config := standardConfig(tmp.Dir(t))
+			config.Product.Repository = "github.com/dadgarcorp/lockbox"
+			config.Product.SourceHash = config.Product.Revision
+			b, err := NewRemoteBuild(config, c.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := b.(*RemoteBuild).sendToken; got != c.want {
+				t.Errorf("got sendToken %t; want %t", got, c.want)
+			}
+		})
+	}
+}
+
+func TestNewRemoteBuild_privateGitHub(t *testing.T) {
+	config := standardConfig(tmp.Dir(t))
+	config.Product.Repository = "github.com/dadgarcorp/lockbox"
+	config.Product.SourceHash = config.Product.Revision
+	cases := []struct {
+		desc, token, wantURL, wantInnerDir string
+	}{
+		{
+			"without token", "",
+			"https://github.com/dadgarcorp/lockbox/archive/" + config.Product.Revision + ".zip",
+			"lockbox-" + config.Product.Revision,
+		},
+		{
+			"with token", "secret",
+			"https://api.github.com/repos/dadgarcorp/lockbox/zipball/" + config.Product.Revision,
+			"",
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			t.Setenv("GITHUB_TOKEN", c.token)
+			b, err := NewRemoteBuild(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rb := b.(*RemoteBuild)
+			if rb.sourceURL != c.wantURL || rb.innerDir != c.wantInnerDir || !rb.sendToken {
+				t.Errorf("got URL %q, inner dir %q, sendToken %t; want %q, %q, true",
+					rb.sourceURL, rb.innerDir, rb.sendToken, c.wantURL, c.wantInnerDir)
+			}
+		})
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/hashicorp/actions-go-build/pkg/crt"
-	"github.com/hashicorp/composite-action-framework-go/pkg/json"
 )
 
 // Inputs represents the fixed inuputs to the build.
@@ -23,18 +22,33 @@ type Inputs struct {
 // Note that the Config will be different for each of
 // them because it contains build-host-specific paths.
 type Result struct {
-	Config          Config
-	Env             []string
-	Meta            Meta
-	Zip             crt.File
-	Executable      crt.File
-	err             error
-	ErrorMessage    string `json:",omitempty"`
-	Successful      bool
-	loadedFromCache bool
+	Config       Config
+	Env          []string
+	Meta         Meta
+	Zip          crt.File
+	Executable   crt.File
+	Image        *crt.Image `json:",omitempty"`
+	Modules      *Modules   `json:",omitempty"`
+	err          error
+	ErrorMessage string `json:",omitempty"`
+	Successful   bool
+	// Steps records each step run, in order, up to and including the
+	// first one that failed.
+	Steps []StepResult `json:",omitempty"`
+	// FromCache is true if this result was loaded from the cache rather
+	// than built. It depends on the run rather than the build, so it's
+	// never written to a document.
+	FromCache bool `json:"-"`
 }
 
-func (br Result) IsFromCache() bool { return br.loadedFromCache }
+// StepResult captures the outcome of a single build step.
+type StepResult struct {
+	Description  string
+	Duration     string
+	ErrorMessage string `json:",omitempty"`
+}
+
+func (br Result) IsFromCache() bool { return br.FromCache }
 
 func (br Result) Error() error {
 	return br.err
@@ -43,7 +57,7 @@ func (br Result) Error() error {
 func (br Result) Save(isVerification bool) (string, error) {
 	// Write the result to meta to cache it.
 	path := br.Config.BuildResultCachePath(isVerification)
-	return path, json.WriteFile(path, br)
+	return path, WriteDocumentFile(path, br)
 }
 
 // Meta captures after-the-fact information about the build.
@@ -4,11 +4,13 @@
 package build
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/hashicorp/actions-go-build/internal/ocilayout"
 	"github.com/hashicorp/actions-go-build/pkg/crt"
 	"github.com/hashicorp/actions-go-build/pkg/digest"
 )
@@ -59,6 +61,11 @@ func (br *Runner) Run() Result {
 		br.recordStep("recording zip file details", func() error {
 			return br.RecordZip(br.build.Config().Paths.ZipPath)
 		})
+		if ociPath := br.build.Config().OCIPath(); ociPath != "" {
+			br.recordStep("recording OCI image details", func() error {
+				return br.RecordImage(ociPath)
+			})
+		}
 	}
 	return br.Result()
 }
@@ -71,6 +78,7 @@ func (br *Runner) finish() {
 	if !br.isFinished() {
 		br.result.Config = br.build.Config()
 		br.result.Env = br.build.Env()
+		br.result.Modules = br.build.Modules()
 		br.result.Meta.Finish = br.nowFunc()
 		br.result.Meta.Duration = br.result.Meta.Finish.Sub(br.result.Meta.Start).String()
 		br.result.Successful = br.result.err == nil
@@ -98,6 +106,24 @@ func (br *Runner) RecordZip(path string) error {
 	return err
 }
 
+func (br *Runner) RecordImage(path string) error {
+	d, err := ocilayout.Inspect(path)
+	if err != nil {
+		return err
+	}
+	img := &crt.Image{
+		Ref:            d.Annotations[ocilayout.AnnotationImageName],
+		ManifestDigest: d.Digest,
+	}
+	// Directory layouts have no single file to describe,
+	// the manifest digest covers their contents.
+	if img.File, err = getFileDetails(path); err != nil && !errors.Is(err, errIsDir) {
+		return err
+	}
+	br.result.Image = img
+	return nil
+}
+
 func (br *Runner) start() *Runner {
 	br.result.Meta.Start = br.nowFunc()
 	return br
@@ -105,7 +131,13 @@ func (br *Runner) start() *Runner {
 
 func (br *Runner) recordStep(desc string, step func() error) error {
 	br.Debug("%s: starting", desc)
+	start := br.nowFunc()
 	err := step()
+	sr := StepResult{Description: desc, Duration: br.nowFunc().Sub(start).String()}
+	if err != nil {
+		sr.ErrorMessage = err.Error()
+	}
+	br.result.Steps = append(br.result.Steps, sr)
 	if err == nil {
 		br.Log("%s: ok", desc)
 		return nil
@@ -118,6 +150,8 @@ func (br *Runner) recordStep(desc string, step func() error) error {
 	return err
 }
 
+var errIsDir = errors.New("is a directory")
+
 func getFileDetails(path string) (crt.File, error) {
 	f := crt.File{
 		Name:         filepath.Base(path),
@@ -127,6 +161,9 @@ func getFileDetails(path string) (crt.File, error) {
 	if err != nil {
 		return f, err
 	}
+	if fi.IsDir() {
+		return f, errIsDir
+	}
 	f.Size = fi.Size()
 	f.SHA256Sum, err = digest.FileSHA256Hex(path)
 	return f, err
@@ -4,7 +4,9 @@
 package build
 
 import (
+	"fmt"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/hashicorp/composite-action-framework-go/pkg/fs"
 	"github.com/hashicorp/composite-action-framework-go/pkg/git"
 	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+	"golang.org/x/mod/module"
 )
 
 func TestRunner_Run_ok(t *testing.T) {
@@ -64,6 +67,46 @@ func TestRunner_Run_err(t *testing.T) {
 	}
 }
 
+func TestRunner_Run_offlineModules(t *testing.T) {
+	dep := module.Version{Path: "example.com/greeting", Version: "v1.0.0"}
+	proxyDir, sum, goModSum := createModuleProxy(t, dep, map[string]string{
+		"go.mod":      "module example.com/greeting\n\ngo 1.24\n",
+		"greeting.go": "package greeting\n\nconst Hello = \"hello, world\"\n",
+	})
+	t.Setenv("GOPROXY", "file://"+proxyDir)
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("TMPDIR", tmp.Dir(t))
+
+	c := standardConfig(tmp.Dir(t))
+	c.Product.SourceHash = c.Product.Revision
+	// The build fails unless it's offline, and so uses the prefetched modules.
+	c.Parameters.Instructions = `test "$GOPROXY" = off && go build -o $BIN_PATH`
+	testBuild, err := New("test-build", c, WithOfflineModules(true))
+	must(t, err)
+	b := testBuild.(*core)
+	b.writeTestFile(t, "main.go", "package main\n\nimport \"example.com/greeting\"\n\nfunc main() { println(greeting.Hello) }\n")
+	b.writeTestFile(t, "go.mod", goDotMod+"\nrequire example.com/greeting v1.0.0\n")
+	b.writeTestFile(t, "go.sum", fmt.Sprintf("%[1]s %[2]s %[3]s\n%[1]s %[2]s/go.mod %[4]s\n", dep.Path, dep.Version, sum, goModSum))
+	r, err := NewRunner(b)
+	must(t, err)
+	result := r.Run()
+	must(t, result.Error())
+
+	got := result.Modules
+	if got == nil {
+		t.Fatal("got nil Modules")
+	}
+	if want := []string{"example.com/greeting@v1.0.0"}; !reflect.DeepEqual(got.List, want) {
+		t.Errorf("got modules %q; want %q", got.List, want)
+	}
+	if len(got.GoSumSHA256) != 64 {
+		t.Errorf("got go.sum hash %q; want a SHA-256", got.GoSumSHA256)
+	}
+	if !got.Offline {
+		t.Errorf("got Offline false; want true")
+	}
+}
+
 const mainDotGo = `
 	package main
 
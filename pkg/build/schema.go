@@ -0,0 +1,54 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/actions-go-build/internal/jsonschema"
+)
+
+// SchemaDir is where the JSON Schemas for each kind of document are
+// published, relative to the repository root. Schemas for older versions are
+// kept alongside the current ones.
+const SchemaDir = "schemas"
+
+// schemaFileStems are the stems of each kind's schema file names.
+var schemaFileStems = map[string]string{
+	KindConfig:             "build-config",
+	KindResult:             "build-result",
+	KindVerificationResult: "verification-result",
+}
+
+// SchemaFileName returns the name of the schema file for kind at version.
+func SchemaFileName(kind string, version int) string {
+	return fmt.Sprintf("%s.v%d.schema.json", schemaFileStems[kind], version)
+}
+
+// Schemas returns the JSON Schema for each kind of document at the current
+// SchemaVersion, keyed by SchemaFileName.
+func Schemas() (map[string][]byte, error) {
+	out := map[string][]byte{}
+	for kind, v := range map[string]any{
+		KindConfig:             Config{},
+		KindResult:             Result{},
+		KindVerificationResult: VerificationResult{},
+	} {
+		s, err := jsonschema.Generate(v)
+		if err != nil {
+			return nil, err
+		}
+		s.Title = fmt.Sprintf("actions-go-build %s, schema version %d", kind, SchemaVersion)
+		s.Properties["Kind"] = &jsonschema.Schema{Type: "string", Const: kind}
+		s.Properties["SchemaVersion"] = &jsonschema.Schema{Type: "integer", Const: SchemaVersion}
+		s.Required = append([]string{"Kind", "SchemaVersion"}, s.Required...)
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		out[SchemaFileName(kind, SchemaVersion)] = append(data, '\n')
+	}
+	return out, nil
+}
@@ -7,10 +7,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"os/exec"
 
+	"github.com/hashicorp/actions-go-build/internal/fetch"
 	"github.com/hashicorp/actions-go-build/internal/log"
+	"github.com/hashicorp/actions-go-build/internal/source"
+	"github.com/hashicorp/actions-go-build/internal/unzipper"
 )
 
 // Settings contains settings for running builds.
@@ -32,6 +36,29 @@ type Settings struct {
 	isVerification bool
 	cleanOnly      bool
 	logPrefix      string
+	unzipLimits    *unzipper.Limits
+	// fetchClient is used for all HTTP downloads.
+	fetchClient *fetch.Client
+	// sourceProviders is keyed by repository host.
+	sourceProviders source.Providers
+	// tokenEnv is the environment variable containing the token sent
+	// when downloading from GitHub.
+	tokenEnv string
+	// sourceMode is how remote builds get source code: SourceArchive or SourceGit.
+	sourceMode string
+	// cloneURL overrides the repository URL cloned in SourceGit mode.
+	cloneURL string
+	// prefetchModules downloads modules into a dedicated module cache
+	// before running the instructions.
+	prefetchModules bool
+	// offlineModules runs the instructions with GOPROXY=off against the
+	// prefetched module cache.
+	offlineModules bool
+	// goproxy is the GOPROXY-style list of proxies used in SourceModule mode.
+	goproxy string
+	// moduleSum is the expected hash of the module zip in SourceModule
+	// mode, or a go.sum file containing it.
+	moduleSum string
 }
 
 // Option represents a function that configures Settings.
@@ -82,6 +109,92 @@ func AsPrimaryBuild() Option { return func(s *Settings) { s.isVerification = fal
 // WithCleanOnly causes the build to fail early if it's not based on a clean worktree.
 func WithCleanOnly(on bool) Option { return func(s *Settings) { s.cleanOnly = on } }
 
+// WithUnzipLimits overrides unzipper.DefaultLimits when extracting
+// downloaded source archives.
+func WithUnzipLimits(l unzipper.Limits) Option {
+	return func(s *Settings) { s.unzipLimits = &l }
+}
+
+// WithSourceProvider sets the provider used by remote builds to download
+// source code for repositories hosted on host. Providers for well-known
+// forges are configured by default.
+func WithSourceProvider(host string, p source.Provider) Option {
+	return func(s *Settings) {
+		if s.sourceProviders == nil {
+			s.sourceProviders = source.Providers{}
+		}
+		s.sourceProviders[host] = p
+	}
+}
+
+// WithTokenEnv sets the environment variable to read a GitHub token from,
+// for downloading source code from private repositories. Defaults to
+// GITHUB_TOKEN.
+func WithTokenEnv(name string) Option { return func(s *Settings) { s.tokenEnv = name } }
+
+// WithSourceMode sets how remote builds get source code: SourceArchive
+// (the default), SourceGit, or SourceModule.
+func WithSourceMode(m string) Option { return func(s *Settings) { s.sourceMode = m } }
+
+// WithCloneURL sets the URL or local path of the repository cloned by remote
+// builds in SourceGit mode, e.g. a mirror or a local bare repository. By
+// default, the product repository is cloned.
+func WithCloneURL(u string) Option { return func(s *Settings) { s.cloneURL = u } }
+
+// WithFetchClient sets the HTTP client used to download source code.
+// Defaults to a client with fetch's default timeouts, retries, and size limit.
+func WithFetchClient(c *fetch.Client) Option { return func(s *Settings) { s.fetchClient = c } }
+
+// WithPrefetchModules runs `go mod download` into a module cache dedicated to
+// this build before running the instructions, and records the modules
+// downloaded in the result.
+func WithPrefetchModules(on bool) Option { return func(s *Settings) { s.prefetchModules = on } }
+
+// WithOfflineModules runs the instructions with GOPROXY=off and
+// GOFLAGS=-mod=readonly, so they can only use the prefetched modules.
+// It implies WithPrefetchModules.
+func WithOfflineModules(on bool) Option { return func(s *Settings) { s.offlineModules = on } }
+
+// WithGOPROXY sets the module proxy used by remote builds in SourceModule
+// mode, in the same format as the GOPROXY environment variable. Defaults to
+// $GOPROXY, or else the public Go module proxy.
+func WithGOPROXY(p string) Option { return func(s *Settings) { s.goproxy = p } }
+
+// WithModuleSum sets the expected go.sum-style hash of the module zip used
+// in SourceModule mode, e.g. "h1:abc...=", or the path to a go.sum file
+// containing it.
+func WithModuleSum(sum string) Option { return func(s *Settings) { s.moduleSum = sum } }
+
+// GitHubToken returns the token to send when downloading from GitHub.
+func (s *Settings) GitHubToken() fetch.Token {
+	return fetch.Token{Env: s.tokenEnv, Value: os.Getenv(s.tokenEnv)}
+}
+
+// sendsTokenTo returns true if the GitHub token may be sent with requests to
+// rawURL, which must use https and be on a GitHub host.
+func (s *Settings) sendsTokenTo(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && u.Scheme == "https" && s.sourceProviders.IsGitHub(u.Host)
+}
+
+// TokenFunc returns a function which returns the token to send to host:
+// the GitHub token if host is GitHub according to the source providers in
+// options, or else the zero Token. Builds configured with the same options
+// make the same decision.
+func TokenFunc(options ...Option) func(host string) fetch.Token {
+	s := &Settings{}
+	for _, o := range options {
+		o(s)
+	}
+	s.setSourceDefaults()
+	return func(host string) fetch.Token {
+		if !s.sourceProviders.IsGitHub(host) {
+			return fetch.Token{}
+		}
+		return s.GitHubToken()
+	}
+}
+
 func newSettings(options []Option) (Settings, error) {
 	s := &Settings{}
 	err := s.setOptions(options...)
@@ -95,6 +208,19 @@ func (s *Settings) setOptions(opts ...Option) error {
 	return s.setDefaults()
 }
 
+// setSourceDefaults sets the defaults which decide where source is
+// downloaded from, and where the GitHub token is sent.
+func (s *Settings) setSourceDefaults() {
+	if s.tokenEnv == "" {
+		s.tokenEnv = "GITHUB_TOKEN"
+	}
+	for host, p := range source.DefaultProviders() {
+		if _, ok := s.sourceProviders[host]; !ok {
+			WithSourceProvider(host, p)(s)
+		}
+	}
+}
+
 func (s *Settings) setDefaults() (err error) {
 	s.bash, err = resolveBashPath(s.bash)
 	if err != nil {
@@ -103,6 +229,16 @@ func (s *Settings) setDefaults() (err error) {
 	if s.context == nil {
 		s.context = context.Background()
 	}
+	if s.sourceMode == "" {
+		s.sourceMode = SourceArchive
+	}
+	if s.offlineModules {
+		s.prefetchModules = true
+	}
+	if s.goproxy == "" {
+		s.goproxy = os.Getenv("GOPROXY")
+	}
+	s.setSourceDefaults()
 	if s.Debug == nil {
 		s.Debug = log.Debug
 	}
@@ -116,6 +252,9 @@ func (s *Settings) setDefaults() (err error) {
 	WithDebugfunc(s.Debug)(s)
 	WithLogfunc(s.Log)(s)
 	WithLoudfunc(s.Loud)(s)
+	if s.fetchClient == nil {
+		s.fetchClient = fetch.New(fetch.WithLog(s.Log))
+	}
 	if s.stdout == nil {
 		s.stdout = os.Stderr
 	}
@@ -76,6 +76,11 @@ func (d TempDirs) SourceDownloadDir() string {
 	return d.cacheDir("sourcearchive")
 }
 
+// ModCacheDir is the GOMODCACHE modules are prefetched into.
+func (d TempDirs) ModCacheDir() string {
+	return d.cacheDir("gomodcache")
+}
+
 func (d TempDirs) BuildResultCacheDir(extension ...string) string {
 	return d.cacheDir("buildresult", extension...)
 }
@@ -19,6 +19,11 @@ type VerificationResult struct {
 	ErrorMessage        string `json:",omitempty"`
 	Dirty               bool
 	ReproducedCorrectly bool
+	// PrimaryFromCache is true if the primary build result was loaded from
+	// the cache rather than built. Results don't record this themselves,
+	// since the same result may be built by one run and loaded by the next;
+	// a verification result only ever describes the run that produced it.
+	PrimaryFromCache bool `json:",omitempty"`
 }
 
 func (vr *VerificationResult) Error() error {
@@ -5,6 +5,7 @@ package build
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/actions-go-build/pkg/crt"
@@ -90,18 +91,28 @@ func (v *Verifier) verificationResult(pr, vr *Result) (*VerificationResult, erro
 	binHashes, binErr := v.fileHashes("executable", pr.Executable, vr.Executable)
 	zipHashes, zipErr := v.fileHashes("zip", pr.Zip, vr.Zip)
 
+	var extra []crt.FileHashes
+	var imageErr error
+	if pr.Image != nil || vr.Image != nil {
+		var imageHashes crt.FileHashes
+		imageHashes, imageErr = v.imageHashes(pr.Image, vr.Image)
+		extra = append(extra, imageHashes)
+	}
+
 	var err error
 	if binErr != nil {
 		err = binErr
 	} else if zipErr != nil {
 		err = zipErr
+	} else if imageErr != nil {
+		err = imageErr
 	}
 	var errMessage string
 	if err != nil {
 		errMessage = err.Error()
 	}
 
-	hashes := crt.NewFileSetHashes(binHashes, zipHashes)
+	hashes := crt.NewFileSetHashes(binHashes, zipHashes, extra...)
 
 	dirty := pr.Config.Product.IsDirty() || vr.Config.Product.IsDirty()
 
@@ -112,6 +123,7 @@ func (v *Verifier) verificationResult(pr, vr *Result) (*VerificationResult, erro
 		ErrorMessage:        errMessage,
 		Dirty:               dirty,
 		ReproducedCorrectly: err == nil,
+		PrimaryFromCache:    pr.FromCache,
 	}, nil
 }
 
@@ -136,3 +148,34 @@ func (v *Verifier) fileHashes(desc string, pf, vf crt.File) (crt.FileHashes, err
 		},
 	}, err
 }
+
+// imageHashes compares OCI images by their manifest digests, which cover
+// the config and every layer, regardless of the layout format on disk.
+func (v *Verifier) imageHashes(pi, vi *crt.Image) (crt.FileHashes, error) {
+	var p, vf crt.Image
+	if pi != nil {
+		p = *pi
+	}
+	if vi != nil {
+		vf = *vi
+	}
+	v.Debug("Comparing primary and verification versions of image: %s", p.Ref)
+	match := p.ManifestDigest == vf.ManifestDigest
+	var err error
+	if pi == nil || vi == nil {
+		err = fmt.Errorf("image only produced by one build")
+	} else if p.Ref != vf.Ref {
+		err = fmt.Errorf("image refs are different: %q and %q", p.Ref, vf.Ref)
+	} else if !match {
+		err = fmt.Errorf("image manifest digests are different: %q and %q", p.ManifestDigest, vf.ManifestDigest)
+	}
+	return crt.FileHashes{
+		Name:        p.Name,
+		Description: "image manifest",
+		SHA256: crt.HashPair{
+			Primary:      strings.TrimPrefix(p.ManifestDigest, "sha256:"),
+			Verification: strings.TrimPrefix(vf.ManifestDigest, "sha256:"),
+			Match:        match,
+		},
+	}, err
+}
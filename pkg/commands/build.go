@@ -6,27 +6,52 @@ package commands
 import (
 	"flag"
 
+	"github.com/hashicorp/actions-go-build/internal/junit"
 	"github.com/hashicorp/composite-action-framework-go/pkg/cli"
 )
 
 type buildOpts struct {
 	buildish
+	stepSummaryOpts
+	junitFile string
 }
 
 func (opts *buildOpts) Flags(fs *flag.FlagSet) {
 	opts.buildish.Flags(fs)
 	fs.BoolVar(&opts.buildFlags.forceVerification, "verification", false, "configure build as a verification build")
 	fs.BoolVar(&opts.buildFlags.requireClean, "clean", false, "fail unless worktree is clean")
+	fs.StringVar(&opts.junitFile, "junit", "", "write a junit xml report to this file")
+	// Unlike verify, build only writes a step summary when asked to, since
+	// the action runs several builds for every verification. For the same
+	// reason it doesn't use STEP_SUMMARY_TEMPLATE, which is rendered against
+	// a verification result.
+	opts.stepSummaryOpts.flags(fs, "", "")
 }
 
 var Build = cli.LeafCommand("build", "run a build", func(opts *buildOpts) error {
-	build, err := opts.build("Running build")
-	if err != nil {
-		return err
+	var suites []junit.TestSuite
+	err := opts.forEachProduct(func(b *buildish) error {
+		build, err := b.build("Running build")
+		if err != nil {
+			return err
+		}
+		result, err := build.Result()
+		if err != nil {
+			return err
+		}
+		suites = append(suites, junit.ForResult(result))
+		// The summary is only informative, so a broken template mustn't
+		// fail an otherwise successful build.
+		if err := opts.stepSummaryOpts.write(&b.logOpts, buildStepSummaryTemplate, result); err != nil {
+			b.loud("Unable to write GitHub Step Summary: %s", err)
+		}
+		return b.output.result(b.desc, result)
+	})
+	if opts.junitFile != "" && len(suites) != 0 {
+		if err := junit.WriteFile(opts.junitFile, "build", suites...); err != nil {
+			return err
+		}
+		opts.log("JUnit report written to %s", opts.junitFile)
 	}
-	result, err := build.Result()
-	if err != nil {
-		return err
-	}
-	return opts.output.result(opts.desc, result)
+	return err
 })
@@ -4,11 +4,15 @@
 package commands
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"io"
 	"os"
 	"time"
 
 	"github.com/hashicorp/actions-go-build/internal/config"
+	"github.com/hashicorp/actions-go-build/internal/source"
 	"github.com/hashicorp/actions-go-build/pkg/build"
 )
 
@@ -16,6 +20,16 @@ import (
 type buildFlags struct {
 	logOpts
 	rebuild bool
+	// sourceProviders are used by remote builds, in addition to the defaults.
+	sourceProviders source.Providers
+	// tokenEnv is the environment variable containing a GitHub token.
+	tokenEnv string
+	// sourceMode and cloneURL configure how remote builds get source code.
+	sourceMode, cloneURL string
+	// goproxy and moduleSum configure the module proxy source mode.
+	goproxy, moduleSum string
+	// prefetchModules and offline configure the prefetched module cache.
+	prefetchModules, offline bool
 
 	// requireClean and forceVerification are not exposed as flags by default.
 	// If a command wants to expose these options it needs to add
@@ -55,6 +69,19 @@ func (flags *buildFlags) Flags(fs *flag.FlagSet) {
 
 func (flags *buildFlags) ownFlags(fs *flag.FlagSet) {
 	fs.BoolVar(&flags.rebuild, "rebuild", false, "re-run the build even if cached")
+	fs.StringVar(&flags.tokenEnv, "token-env", "GITHUB_TOKEN", "environment variable containing a token for downloading from private GitHub repositories")
+	fs.StringVar(&flags.sourceMode, "source-mode", build.SourceArchive, fmt.Sprintf("how remote builds get source code: %q downloads a zip, %q clones the repository with submodules, %q downloads the module zip from a module proxy", build.SourceArchive, build.SourceGit, build.SourceModule))
+	fs.StringVar(&flags.cloneURL, "clone-url", "", "repository URL or local path to clone when -source-mode=git, e.g. a mirror or bare repository")
+	fs.StringVar(&flags.goproxy, "goproxy", "", "module proxy to use when -source-mode=module, in GOPROXY format; may be a file:// URL (default $GOPROXY or https://proxy.golang.org)")
+	fs.StringVar(&flags.moduleSum, "module-sum", "", "expected h1: hash of the module zip when -source-mode=module, or a go.sum file containing it")
+	fs.BoolVar(&flags.prefetchModules, "prefetch-modules", false, "download Go modules into a dedicated module cache before building, and record them in the result")
+	fs.BoolVar(&flags.offline, "offline", false, "build with GOPROXY=off and GOFLAGS=-mod=readonly against the prefetched module cache; implies -prefetch-modules")
+	fs.Func("source-provider", "where remote builds download source for a host: HOST=github|gitlab|gitea or HOST=URL_TEMPLATE (repeatable)", func(s string) error {
+		if flags.sourceProviders == nil {
+			flags.sourceProviders = source.Providers{}
+		}
+		return flags.sourceProviders.ParseProvider(s)
+	})
 }
 
 // A bunch of constructors for things we need configured according to flags.
@@ -101,6 +128,44 @@ func (flags *buildFlags) newVerifier(primary, verification build.ResultSource, e
 	return build.NewVerifier(primary, verification, flags.buildOptions(extraOpts...)...)
 }
 
+// verifyRemotely runs a remote verification build of c, and compares it with
+// primary, or with a remote primary build of c if primary is nil. Logs and
+// build output are written to w, and builds stop when ctx is done.
+func (flags buildFlags) verifyRemotely(ctx context.Context, c build.Config, primary *build.Result, w io.Writer) (*build.VerificationResult, error) {
+	logf := func(f string, a ...any) { fmt.Fprintf(w, f+"\n", a...) }
+	debugf := func(string, ...any) {}
+	if flags.debugFlag {
+		debugf = logf
+	}
+	extraOpts := []build.Option{
+		build.WithContext(ctx),
+		build.WithLogfunc(logf),
+		build.WithLoudfunc(logf),
+		build.WithDebugfunc(debugf),
+		build.WithStdout(w),
+		build.WithStderr(w),
+	}
+	var primarySource build.ResultSource
+	if primary != nil {
+		primarySource = *primary
+	} else {
+		m, err := flags.newRemotePrimaryManager(c, append(extraOpts, build.WithLogPrefix("primary build"))...)
+		if err != nil {
+			return nil, err
+		}
+		primarySource = m
+	}
+	verification, err := flags.newRemoteVerificationManager(c, append(extraOpts, build.WithLogPrefix("verification build"))...)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := flags.newVerifier(primarySource, verification, extraOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return verifier.Verify()
+}
+
 func (flags *buildFlags) manager(b build.Build, err error, extraOpts ...build.Option) (*build.Manager, error) {
 	if err != nil {
 		return nil, err
@@ -120,7 +185,17 @@ func (flags *buildFlags) buildOptions(extraOpts ...build.Option) []build.Option
 	if flags.forceVerification {
 		extraOpts = append(extraOpts, build.AsVerificationBuild())
 	}
+	for host, p := range flags.sourceProviders {
+		extraOpts = append(extraOpts, build.WithSourceProvider(host, p))
+	}
 	return append(flags.logOpts.buildOptions(extraOpts...),
+		build.WithTokenEnv(flags.tokenEnv),
+		build.WithSourceMode(flags.sourceMode),
+		build.WithCloneURL(flags.cloneURL),
+		build.WithGOPROXY(flags.goproxy),
+		build.WithModuleSum(flags.moduleSum),
+		build.WithPrefetchModules(flags.prefetchModules),
+		build.WithOfflineModules(flags.offline),
 		build.WithForceRebuild(flags.rebuild),
 		build.WithCleanOnly(flags.requireClean),
 	)
@@ -4,22 +4,21 @@
 package commands
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
 	"os"
-	"path"
 	"path/filepath"
-	"reflect"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/actions-go-build/internal/config"
+	"github.com/hashicorp/actions-go-build/internal/fetch"
 	"github.com/hashicorp/actions-go-build/pkg/build"
 	"github.com/hashicorp/composite-action-framework-go/pkg/cli"
 	"github.com/hashicorp/composite-action-framework-go/pkg/fs"
-	"github.com/hashicorp/composite-action-framework-go/pkg/json"
 )
 
 type buildFunc func() (*build.Manager, error)
@@ -50,6 +49,7 @@ type buildish struct {
 	logOpts
 	buildFlags buildFlags
 	output     output
+	trust      trustOpts
 
 	// target is the only arg
 	target string
@@ -59,6 +59,10 @@ type buildish struct {
 	buildResult *build.Result
 	buildConfig *build.Config
 	dir         string
+
+	// product is set when building one of several products declared in
+	// the target directory.
+	product *config.ProductSpec
 }
 
 // defaultTarget is the default build to target.
@@ -66,7 +70,7 @@ type buildish struct {
 const defaultTarget = "."
 
 func (b *buildish) Flags(fs *flag.FlagSet) {
-	cli.FlagFuncsAll(fs, b.logOpts.Flags, b.buildFlags.ownFlags, b.output.ownFlags)
+	cli.FlagFuncsAll(fs, b.logOpts.Flags, b.buildFlags.ownFlags, b.output.ownFlags, b.trust.flags)
 }
 
 func (b *buildish) Args(args *cli.ArgList) {
@@ -76,7 +80,7 @@ func (b *buildish) Args(args *cli.ArgList) {
 func (b *buildish) Init() error {
 	b.buildFlags.logOpts = b.logOpts
 	b.output.logOpts = b.logOpts
-	return nil
+	return b.trust.load()
 }
 
 // build is used by consumers of buildish who want a fully-formed build manager which they
@@ -148,6 +152,10 @@ func (b *buildish) getBuildFunc(why string, extraOpts ...build.Option) (buildFun
 	return build, err
 }
 
+// maxConfigSize is the largest build config, build result, or verification
+// result we'll download. Real ones are a few kilobytes.
+const maxConfigSize = 16 << 20
+
 // localFileConfigSource returns a buildFunc which derives build config from a JSON blob retrieved via HTTPS.
 func (b *buildish) urlConfigSource(maybeURL string, extraOpts ...build.Option) (buildFunc, bool, error) {
 	u, err := url.Parse(maybeURL)
@@ -158,8 +166,9 @@ func (b *buildish) urlConfigSource(maybeURL string, extraOpts ...build.Option) (
 		return nil, false, fmt.Errorf("URLs must use https scheme")
 	}
 	return b.configSourceFromReadCloser(maybeURL, func() (io.ReadCloser, error) {
-		resp, err := http.Get(maybeURL)
-		return resp.Body, err
+		client := fetch.New(fetch.WithLog(b.debug), fetch.WithMaxSize(maxConfigSize))
+		token := build.TokenFunc(b.buildFlags.buildOptions()...)(u.Host)
+		return client.Get(context.Background(), maybeURL, token)
 	}, extraOpts...), true, err
 }
 
@@ -176,7 +185,15 @@ func (b *buildish) localFileConfigSource(maybeFile string, extraOpts ...build.Op
 func (b *buildish) localDirConfigSource(maybeDir string, extraOpts ...build.Option) (buildFunc, bool, error) {
 	absDir, exists, err := b.resolvePath("dir", maybeDir, fs.DirExists)
 	return func() (*build.Manager, error) {
-		c, err := config.FromEnvironment(tool, absDir)
+		var c config.Config
+		var err error
+		if b.product != nil {
+			// The whole repository is copied for verification, so that
+			// products can share files outside their own directory.
+			c, err = config.FromEnvironmentForProduct(tool, absDir, *b.product)
+		} else {
+			c, err = config.FromEnvironment(tool, absDir)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -189,7 +206,7 @@ func (b *buildish) localDirConfigSource(maybeDir string, extraOpts ...build.Opti
 		var m *build.Manager
 		if b.buildFlags.forceVerification {
 			startTime := time.Now()
-			if m, err = b.buildFlags.newLocalVerificationManager(maybeDir, startTime, bc, extraOpts...); err != nil {
+			if m, err = b.buildFlags.newLocalVerificationManager(absDir, startTime, bc, extraOpts...); err != nil {
 				return nil, err
 			}
 		} else if m, err = b.buildFlags.newPrimaryManager(bc, extraOpts...); err != nil {
@@ -200,6 +217,53 @@ func (b *buildish) localDirConfigSource(maybeDir string, extraOpts ...build.Opti
 	}, exists, err
 }
 
+// declaredProducts returns the products declared in the target directory, or
+// nil if the target isn't a directory declaring several products.
+func (b *buildish) declaredProducts() ([]config.ProductSpec, error) {
+	if b.product != nil {
+		return nil, nil
+	}
+	absDir, exists, err := b.resolvePath("dir", b.target, fs.DirExists)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return config.LoadProducts(absDir)
+}
+
+// forEachProduct calls fn once for each product declared in the target
+// directory, or just once for the target itself if it doesn't declare any.
+func (b *buildish) forEachProduct(fn func(*buildish) error) error {
+	products, err := b.declaredProducts()
+	if err != nil {
+		return err
+	}
+	if len(products) == 0 {
+		return fn(b)
+	}
+	return eachProduct(&b.logOpts, products, func(p config.ProductSpec) error {
+		pb := *b
+		pb.product = &p
+		return fn(&pb)
+	})
+}
+
+// eachProduct calls fn for each product, carrying on after failures so that
+// every product is built, and returns an error if any failed.
+func eachProduct(l *logOpts, products []config.ProductSpec, fn func(config.ProductSpec) error) error {
+	var failed []string
+	for _, p := range products {
+		l.loud("Product %s (%s)", p.DisplayName(), p.Dir)
+		if err := fn(p); err != nil {
+			l.loud("Product %s failed: %s", p.DisplayName(), err)
+			failed = append(failed, p.DisplayName())
+		}
+	}
+	if len(failed) != 0 {
+		return fmt.Errorf("%d of %d products failed: %s", len(failed), len(products), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
 // resolvePath returns the absolute version of maybePath, alongside a boolean indicating
 // if that path passes the existsFunc test. The kind parameter is used to make logging richer.
 func (b *buildish) resolvePath(kind, maybePath string, existsFunc func(string) (bool, error)) (string, bool, error) {
@@ -237,7 +301,7 @@ func (b *buildish) configSourceFromReadCloser(location string, rcFunc func() (io
 		}
 		var closeErr error
 		defer func() { closeErr = rc.Close() }()
-		c, err := b.readConfig(rc)
+		c, err := b.readConfig(location, rc)
 		if err != nil {
 			return nil, fmt.Errorf("unable to read build config from %q: %w", location, err)
 		}
@@ -259,49 +323,58 @@ func (b *buildish) configSourceFromReadCloser(location string, rcFunc func() (io
 	}
 }
 
-// readConfig attempts to interpret the bytes from an io.Reader as one of three possible
-// structs: a build.Config, build.Result, or build.VerificationResult. All three of
-// these structs contain complete build configuration needed to run a build.
+// readConfig reads a build.Config, build.Result, or build.VerificationResult
+// from an io.Reader, dispatching on the document's Kind, and upgrading it if
+// it was written by an older version. All three of these contain complete
+// build configuration needed to run a build.
 //
 // This is intended to make the system flexible: given any of these three things, you
 // can attempt to reproduce the build they represent.
-func (b *buildish) readConfig(r io.Reader) (build.Config, error) {
+//
+// Any of them may be signed, in which case the signature is checked according to the
+// trust flags before the document is read.
+func (b *buildish) readConfig(location string, r io.Reader) (build.Config, error) {
 	data, err := io.ReadAll(r)
 	if err != nil {
 		return build.Config{}, err
 	}
-	if c, ok := tryUnmarshalJSON[build.Config](b, data); ok {
-		b.debug("%s is build config", b.target)
+	if data, err = b.trust.open(&b.logOpts, location, data); err != nil {
+		return build.Config{}, err
+	}
+	kind, _, err := build.UpgradeDocument(data)
+	if err != nil {
+		return build.Config{}, err
+	}
+	b.debug("%s is a %s", b.target, kind)
+	switch kind {
+	case build.KindConfig:
+		c, err := build.ReadDocument[build.Config](data)
+		if err != nil {
+			return c, err
+		}
 		b.buildConfig = &c
 		return c, nil
-	}
-	if br, ok := tryUnmarshalJSON[build.Result](b, data); ok {
-		b.debug("%s is a build result", b.target)
+	case build.KindResult:
+		br, err := build.ReadDocument[build.Result](data)
+		if err != nil {
+			return br.Config, err
+		}
 		b.buildResult = &br
 		b.buildConfig = &br.Config
 		return br.Config, nil
-	}
-	if vr, ok := tryUnmarshalJSON[build.VerificationResult](b, data); ok {
-		b.debug("%s is a verification result", b.target)
+	case build.KindVerificationResult:
+		vr, err := build.ReadDocument[build.VerificationResult](data)
+		if err != nil {
+			return build.Config{}, err
+		}
+		if vr.Primary == nil {
+			return build.Config{}, fmt.Errorf("verification result has no primary build result")
+		}
 		b.buildResult = vr.Primary
 		b.buildConfig = &vr.Primary.Config
 		return vr.Primary.Config, nil
 	}
-	return build.Config{}, fmt.Errorf("not a build config, build result, or verification result")
-}
-
-// tryUnmarshalJSON attempts to intepret data as a T, and returns a T and true if successful,
-// and returns a zero T and false otherwise.
-func tryUnmarshalJSON[T any](b *buildish, data []byte) (T, bool) {
-	t := reflect.TypeOf(*(new(T)))
-	a, err := json.ReadBytes[T](data)
-	what := fmt.Sprintf("%s.%s", path.Base(t.PkgPath()), t.Name())
-	if err != nil {
-		b.debug("%s is not a valid %s: %s", b.target, what, err)
-	} else {
-		b.debug("%s is is a valid %s", b.target, what)
-	}
-	return a, err == nil
+	return build.Config{}, fmt.Errorf("%s is not a build config, build result, or verification result", kind)
 }
 
 // ensureAbs takes a path and ensures it's absolute relative to the current working directory.
@@ -5,9 +5,14 @@ package commands
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/actions-go-build/internal/config"
 	"github.com/hashicorp/actions-go-build/pkg/build"
+	"github.com/hashicorp/composite-action-framework-go/pkg/git"
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
 )
 
 func test[T any](t *testing.T, desc string, setup func(*buildish), assert func(T, build.Config) error) {
@@ -118,3 +123,90 @@ func TestBuildish_build(t *testing.T) {
 		})
 
 }
+
+func TestBuildish_forEachProduct(t *testing.T) {
+	root := tmp.Dir(t)
+	for name, contents := range map[string]string{
+		config.ProductsFile: `{"Products": [
+			{"Dir": "cmd/lockbox"},
+			{"Dir": "cmd/keyring", "Name": "keyring-tool", "VersionStrategy": "file:VERSION.txt", "Instructions": "make keyring"}
+		]}`,
+		"cmd/lockbox/.release/VERSION": "1.2.3",
+		"cmd/keyring/VERSION.txt":      "4.5.6",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo, err := git.Init(root, git.WithAuthor("test", "test@test.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Add("."); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Commit("initial commit"); err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.HeadCommit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A change in keyring's directory makes both products dirty, since the
+	// whole repository is built. Build output in lockbox's directory doesn't.
+	if err := os.WriteFile(filepath.Join(root, "cmd/keyring/new.go"), []byte("package main"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "cmd/lockbox/dist"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "cmd/lockbox/dist/lockbox"), []byte("binary"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []build.Config
+	b := buildish{target: root}
+	err = b.forEachProduct(func(pb *buildish) error {
+		m, err := pb.build("testing")
+		if err != nil {
+			return err
+		}
+		got = append(got, m.Build().Config())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d builds; want 2", len(got))
+	}
+	lockbox, keyring := got[0], got[1]
+
+	for _, c := range []struct {
+		desc, got, want string
+	}{
+		{"lockbox name", lockbox.Product.Name, "lockbox"},
+		{"lockbox version", lockbox.Product.Version.Full, "1.2.3"},
+		{"lockbox work dir", lockbox.Paths.WorkDir, filepath.Join(root, "cmd/lockbox")},
+		{"lockbox dirty files", strings.Join(lockbox.Product.DirtyFiles, ","), "cmd/keyring/new.go"},
+		{"keyring name", keyring.Product.Name, "keyring-tool"},
+		{"keyring version", keyring.Product.Version.Full, "4.5.6"},
+		{"keyring work dir", keyring.Paths.WorkDir, filepath.Join(root, "cmd/keyring")},
+		{"keyring instructions", keyring.Parameters.Instructions, "make keyring"},
+		{"keyring dirty files", strings.Join(keyring.Product.DirtyFiles, ","), "cmd/keyring/new.go"},
+	} {
+		if c.got != c.want {
+			t.Errorf("got %s %q; want %q", c.desc, c.got, c.want)
+		}
+	}
+	if keyring.Product.SourceHash == head.ID || lockbox.Product.SourceHash != keyring.Product.SourceHash {
+		t.Errorf("got source hashes %q and %q; want the same dirty hash", lockbox.Product.SourceHash, keyring.Product.SourceHash)
+	}
+	if lockbox.BuildResultCachePath(false) == keyring.BuildResultCachePath(false) {
+		t.Errorf("got the same cache path for both products: %q", lockbox.BuildResultCachePath(false))
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	"github.com/hashicorp/composite-action-framework-go/pkg/cli"
+)
+
+type compareOpts struct {
+	logOpts
+	trust trustOpts
+	a, b  string
+	json  bool
+}
+
+func (opts *compareOpts) Flags(fs *flag.FlagSet) {
+	opts.logOpts.Flags(fs)
+	fs.BoolVar(&opts.json, "json", false, "print the comparison as json")
+	opts.trust.flags(fs)
+}
+
+func (opts *compareOpts) Init() error { return opts.trust.load() }
+
+func (opts *compareOpts) Args(args *cli.ArgList) {
+	args.Required(&opts.a, "a")
+	args.Optional(&opts.b, "b", "")
+}
+
+var Compare = cli.LeafCommand("compare", "explain the differences between two builds", func(opts *compareOpts) error {
+	c, err := opts.compare()
+	if err != nil {
+		return err
+	}
+	if opts.json {
+		if err := dumpJSON(stdout, c); err != nil {
+			return err
+		}
+	} else if err := printComparison(stdout, c); err != nil {
+		return err
+	}
+	if c.AffectsOutput() {
+		return errors.New("the builds differ in ways expected to affect their output")
+	}
+	return nil
+}).WithHelp(`
+Compare two build configs, build results, or verification results, and print their
+differences grouped by category: Product, Parameters, Modules, Tool, Env, Paths, Meta,
+and Artifacts. Each difference says whether it's expected to affect the build output.
+Paths, timings, the tool that ran the build, and build-specific environment variables
+are expected to differ between builds that reproduce each other.
+
+A verification result is compared using its primary build result. If only one file is
+given, it must be a verification result, and its primary and verification build results
+are compared.
+
+Configs are only compared with the config in the other file, since they don't have an
+environment, metadata, or artifacts.
+
+Exits with a non-zero status if any difference is expected to affect the output, or the
+artifacts themselves differ.
+`)
+
+func (opts *compareOpts) compare() (build.Comparison, error) {
+	if opts.b == "" {
+		data, err := opts.readFile(opts.a)
+		if err != nil {
+			return build.Comparison{}, err
+		}
+		vr, err := build.ReadDocument[build.VerificationResult](data)
+		if err != nil {
+			return build.Comparison{}, fmt.Errorf("reading %s: %w", opts.a, err)
+		}
+		if vr.Primary == nil || vr.Verification == nil {
+			return build.Comparison{}, fmt.Errorf("%s is missing a primary or verification build result", opts.a)
+		}
+		return build.Compare(*vr.Primary, *vr.Verification), nil
+	}
+	a, aIsResult, err := opts.readComparable(opts.a)
+	if err != nil {
+		return build.Comparison{}, err
+	}
+	b, bIsResult, err := opts.readComparable(opts.b)
+	if err != nil {
+		return build.Comparison{}, err
+	}
+	if !aIsResult || !bIsResult {
+		opts.log("Only comparing build configs, since at least one file is a build config.")
+		return build.CompareConfigs(a.Config, b.Config), nil
+	}
+	return build.Compare(a, b), nil
+}
+
+// readFile reads the document in the file at path, which may be signed.
+func (opts *compareOpts) readFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return opts.trust.open(&opts.logOpts, path, data)
+}
+
+// readComparable reads a document, returning the build result it describes,
+// or a result containing only its config and false if it's a build config.
+func (opts *compareOpts) readComparable(path string) (build.Result, bool, error) {
+	data, err := opts.readFile(path)
+	if err != nil {
+		return build.Result{}, false, err
+	}
+	kind, _, err := build.UpgradeDocument(data)
+	if err != nil {
+		return build.Result{}, false, fmt.Errorf("reading %s: %w", path, err)
+	}
+	switch kind {
+	case build.KindConfig:
+		c, err := build.ReadDocument[build.Config](data)
+		return build.Result{Config: c}, false, err
+	case build.KindResult:
+		r, err := build.ReadDocument[build.Result](data)
+		return r, true, err
+	}
+	vr, err := build.ReadDocument[build.VerificationResult](data)
+	if err != nil {
+		return build.Result{}, false, err
+	}
+	if vr.Primary == nil {
+		return build.Result{}, false, fmt.Errorf("%s has no primary build result", path)
+	}
+	return *vr.Primary, true, nil
+}
+
+func printComparison(w io.Writer, c build.Comparison) error {
+	p := printer{w: w}
+	var affecting int
+	for _, category := range build.Categories {
+		diffs := c.InCategory(category)
+		if len(diffs) == 0 {
+			continue
+		}
+		if err := p.line("%s:", category); err != nil {
+			return err
+		}
+		for _, d := range diffs {
+			if d.Impact != build.ImpactNone {
+				affecting++
+			}
+			if err := firstErr(
+				func() error { return p.line("  %s (%s)", d.Field, d.Impact) },
+				func() error { return p.line("    - %s", d.A) },
+				func() error { return p.line("    + %s", d.B) },
+			); err != nil {
+				return err
+			}
+		}
+	}
+	if len(c.Differences) == 0 {
+		return p.line("No differences.")
+	}
+	return p.line("%d difference(s), %d expected to affect output.", len(c.Differences), affecting)
+}
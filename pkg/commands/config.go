@@ -6,17 +6,23 @@ package commands
 import (
 	"flag"
 	"fmt"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/hashicorp/actions-go-build/internal/config"
 	"github.com/hashicorp/composite-action-framework-go/pkg/cli"
 )
 
 type configOpts struct {
-	github bool
+	github  bool
+	sources bool
+	format  string
 }
 
 func (c *configOpts) Flags(fs *flag.FlagSet) {
 	fs.BoolVar(&c.github, "github", false, "export config to github env")
+	fs.BoolVar(&c.sources, "sources", false, "show where each value came from")
+	fs.StringVar(&c.format, "format", config.FormatPlain, "output format: "+strings.Join(config.ExportFormats, ", "))
 }
 
 var Config = cli.LeafCommand("config", "print config and export to GITHUB_ENV if set", func(opts *configOpts) error {
@@ -27,22 +33,42 @@ var Config = cli.LeafCommand("config", "print config and export to GITHUB_ENV if
 	if opts.github {
 		return cfg.ExportToGitHubEnv()
 	}
-	return dumpConfig(cfg)
+	if opts.sources {
+		if opts.format != config.FormatPlain {
+			return fmt.Errorf("-sources can't be used with -format %s", opts.format)
+		}
+		return dumpConfigSources(cfg)
+	}
+	return cfg.Export(stdout, opts.format)
 }).WithHelp(`
-Print the current configuration, determined by the environment and repository context.
+Print the current configuration, determined by the checked-in config file, the environment
+and repository context.
+
+Values are read from ` + config.FileName + ` in the repository root if it exists, then from
+environment variables, which take precedence. Anything set by neither is derived from the
+repository or defaulted. Use the -sources flag to show which of these each value came from.
+
+Use the -format flag to print the configuration in a form other tools can read:
+
+  plain          NAME=value lines, for reading (the default).
+  shell          export statements with quoted values, for eval in sh-compatible shells.
+  dotenv         a .env file, with multi-line values escaped.
+  json           a JSON object mapping names to values.
+  github-output  the GITHUB_OUTPUT file format, with multi-line values in heredocs.
 
 Use the -github flag to export the full configuration to GITHUB_ENV. This is used by the
 action to gather configuration from all the inputs as well as the repository context, and
 to store that config so that subsequent steps can use it.
 `)
 
-func dumpConfig(c config.Config) error {
+func dumpConfigSources(c config.Config) error {
 	vars, err := c.EnvVars()
 	if err != nil {
 		return err
 	}
+	tw := tabwriter.NewWriter(stdout, 2, 2, 2, ' ', 0)
 	for _, v := range vars {
-		fmt.Fprintf(stdout, "%s=%s\n", v.Name, v.Value)
+		fmt.Fprintf(tw, "%s\t%s=%s\n", c.Source(v.Name), v.Name, v.Value)
 	}
-	return nil
+	return tw.Flush()
 }
@@ -10,7 +10,6 @@ import (
 
 	"github.com/hashicorp/actions-go-build/pkg/build"
 	"github.com/hashicorp/composite-action-framework-go/pkg/cli"
-	"github.com/hashicorp/composite-action-framework-go/pkg/json"
 )
 
 type inspectOpts struct {
@@ -58,7 +57,7 @@ var Inspect = cli.LeafCommand("inspect", "inspect things", func(opts *inspectOpt
 	}
 
 	if opts.buildConfig {
-		return json.Write(os.Stdout, bm.Build().Config())
+		return build.WriteDocument(os.Stdout, bm.Build().Config())
 	}
 
 	if opts.buildEnv {
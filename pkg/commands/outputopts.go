@@ -5,7 +5,6 @@ package commands
 
 import (
 	"bytes"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -13,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/actions-go-build/internal/log"
+	"github.com/hashicorp/actions-go-build/pkg/build"
 	"github.com/hashicorp/actions-go-build/pkg/crt"
 )
 
@@ -91,8 +91,8 @@ func (p *output) productInfo(product crt.Product) error {
 	return err
 }
 
+// dumpJSON writes v as indented JSON, with its Kind and SchemaVersion if it's
+// a document that can be read back in.
 func dumpJSON(w io.Writer, v any) error {
-	e := json.NewEncoder(w)
-	e.SetIndent("", "  ")
-	return e.Encode(v)
+	return build.WriteDocument(w, v)
 }
@@ -0,0 +1,126 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/internal/fetch"
+	"github.com/hashicorp/actions-go-build/internal/watch"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	"github.com/hashicorp/composite-action-framework-go/pkg/cli"
+)
+
+type rebuildWatchOpts struct {
+	buildFlags buildFlags
+	trust      trustOpts
+	source     string
+	stateDir   string
+	interval   time.Duration
+	recheck    time.Duration
+	once       bool
+}
+
+func (opts *rebuildWatchOpts) Flags(fs *flag.FlagSet) {
+	opts.buildFlags.Flags(fs)
+	fs.StringVar(&opts.stateDir, "state-dir", "rebuild-watch", "directory to keep outcomes in")
+	fs.DurationVar(&opts.interval, "interval", 10*time.Minute, "how often to poll for new results")
+	fs.DurationVar(&opts.recheck, "recheck", 7*24*time.Hour, "how long after a result was last verified to verify it again; 0 to only verify each result once")
+	fs.BoolVar(&opts.once, "once", false, "poll once, then exit")
+	opts.trust.flags(fs)
+}
+
+func (opts *rebuildWatchOpts) Init() error {
+	if err := opts.trust.load(); err != nil {
+		return err
+	}
+	// Verifying a result runs the build instructions in it, so anyone who
+	// can change the index, or the documents it lists, could run commands
+	// on this machine.
+	if !opts.trust.requireSigned && watch.IsIndex(opts.source) {
+		return fmt.Errorf("refusing to watch %s without -require-signed, since anyone who can change "+
+			"the results it lists could run commands on this machine", opts.source)
+	}
+	return nil
+}
+
+func (opts *rebuildWatchOpts) Args(args *cli.ArgList) {
+	args.Required(&opts.source, "source")
+}
+
+var RebuildWatch = cli.LeafCommand("rebuild-watch", "continuously re-verify published build results", func(opts *rebuildWatchOpts) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	l := &opts.buildFlags.logOpts
+	client := fetch.New(fetch.WithLog(l.debug), fetch.WithMaxSize(maxConfigSize))
+	source, err := watch.NewSource(opts.source, client, build.TokenFunc(opts.buildFlags.buildOptions()...))
+	if err != nil {
+		return err
+	}
+	w, err := watch.New(source, opts.stateDir, opts.verify,
+		watch.WithRecheck(opts.recheck),
+		watch.WithLog(l.loudFunc()),
+		watch.WithOpen(func(location string, data []byte) ([]byte, error) {
+			return opts.trust.open(l, location, data)
+		}),
+	)
+	if err != nil {
+		return err
+	}
+	if opts.once {
+		s, err := w.Poll(ctx)
+		if err != nil {
+			return err
+		}
+		opts.report(s)
+		if len(s.Regressions) != 0 {
+			return fmt.Errorf("%d result(s) no longer reproduce", len(s.Regressions))
+		}
+		return nil
+	}
+	l.loud("Watching %s every %s; outcomes are kept in %s", opts.source, opts.interval, opts.stateDir)
+	return w.Run(ctx, opts.interval, opts.report)
+}).WithHelp(`
+Watch SOURCE for published build results, and run a remote verification build of each
+new one. SOURCE is either a directory, which is searched for .json files, or an https
+URL of an index listing the URLs of build results, as a JSON list or one per line.
+Relative URLs in the index are resolved against the index's URL.
+
+Each result is verified again once -recheck has passed since it was last verified, so
+that builds which stop reproducing, e.g. because their source archive changed, are
+caught. Those are reported as regressions.
+
+The outcome of every check, along with its verification result and build logs, is kept
+in -state-dir, so results aren't verified again when the watcher restarts.
+
+With -once, SOURCE is polled once, and the exit status is non-zero if any result
+regressed.
+
+Verifying a result runs the build instructions in it, so results are checked with
+-trusted-key and -require-signed like any other document read, and untrusted ones are
+skipped. Watching an index needs -require-signed, since anyone who can change the index
+or the results it lists could otherwise run commands on this machine.
+`)
+
+func (opts *rebuildWatchOpts) verify(ctx context.Context, r build.Result, w io.Writer) (*build.VerificationResult, error) {
+	return opts.buildFlags.verifyRemotely(ctx, r.Config, &r, w)
+}
+
+func (opts *rebuildWatchOpts) report(s watch.Summary) {
+	l := &opts.buildFlags.logOpts
+	for _, r := range s.Regressions {
+		l.loud("REGRESSION: %s %s %s (%s) reproduced on %s, but doesn't any more: %s",
+			r.Record.Product, r.Record.Version, r.Record.Platform, r.Record.Location,
+			r.Record.LastReproduced.Format(time.RFC3339), r.Check.Error)
+	}
+	l.log("Found %d new result(s), verified %d, %d regression(s).", s.New, s.Checked, len(s.Regressions))
+}
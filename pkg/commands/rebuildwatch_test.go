@@ -0,0 +1,45 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package commands
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/actions-go-build/internal/signing"
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+func TestRebuildWatchOpts_Init(t *testing.T) {
+	dir := tmp.Dir(t)
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := filepath.Join(dir, "key.pub")
+	if err := signing.WriteKeyFiles(key, filepath.Join(dir, "key.pem"), pub); err != nil {
+		t.Fatal(err)
+	}
+	signed := trustOpts{keyFiles: []string{pub}, requireSigned: true}
+
+	cases := []struct {
+		source  string
+		trust   trustOpts
+		wantErr bool
+	}{
+		{dir, trustOpts{}, false},
+		{dir, signed, false},
+		{"https://example.com/index.txt", trustOpts{}, true},
+		{"https://example.com/index.txt", trustOpts{keyFiles: []string{pub}}, true},
+		{"https://example.com/index.txt", signed, false},
+	}
+	for _, c := range cases {
+		opts := rebuildWatchOpts{source: c.source, trust: c.trust}
+		if err := opts.Init(); (err != nil) != c.wantErr {
+			t.Errorf("Init with source %s and %+v: got error %v; want error: %t", c.source, c.trust, err, c.wantErr)
+		}
+	}
+}
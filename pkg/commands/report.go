@@ -0,0 +1,110 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/actions-go-build/internal/report"
+	"github.com/hashicorp/composite-action-framework-go/pkg/cli"
+	"github.com/hashicorp/composite-action-framework-go/pkg/fs"
+)
+
+type reportOpts struct {
+	logOpts
+	trust       trustOpts
+	paths       []string
+	format      string
+	outFile     string
+	stepSummary string
+}
+
+func (opts *reportOpts) Flags(fs *flag.FlagSet) {
+	opts.logOpts.Flags(fs)
+	fs.StringVar(&opts.format, "format", report.FormatMarkdown, "output format: "+strings.Join(report.Formats, ", "))
+	fs.StringVar(&opts.outFile, "o", "", "write the report to this file instead of stdout")
+	fs.StringVar(&opts.stepSummary, "github-step-summary", os.Getenv("GITHUB_STEP_SUMMARY"), "append a markdown report to this file")
+	opts.trust.flags(fs)
+}
+
+func (opts *reportOpts) Init() error { return opts.trust.load() }
+
+func (opts *reportOpts) Args(args *cli.ArgList) {
+	args.RequiredVariadic(&opts.paths, "PATH", 1)
+}
+
+var Report = cli.LeafCommand("report", "summarise build and verification results across platforms", func(opts *reportOpts) error {
+	r, err := report.Load(opts.trust.trust(&opts.logOpts), opts.paths...)
+	if err != nil {
+		return err
+	}
+	for _, path := range r.Skipped {
+		opts.debug("Skipped %s: not a trusted build or verification result", path)
+	}
+	if err := opts.write(r); err != nil {
+		return err
+	}
+	if err := opts.writeStepSummary(r); err != nil {
+		return err
+	}
+	if !r.OK() {
+		return errors.New("some builds failed or weren't reproduced")
+	}
+	return nil
+}).WithHelp(`
+Read build results and verification results, and summarise them as a matrix of
+platforms and their statuses: reproduced, not reproduced, built (but not verified), or
+failed. The matrix shows the artifact hashes, whether each build was dirty, whether
+verification loaded the primary build from the cache, and how long each build took,
+grouped by product and version.
+
+Each PATH is either a file containing a result or a list of results, such as those
+written by 'verify -o', or a directory which is searched for them. Files in directories
+which aren't results are skipped, but each file given explicitly must be a result.
+
+The report is printed as markdown by default, or as json or a self-contained html page
+with -format. If GITHUB_STEP_SUMMARY is set, a markdown report is also appended to it.
+
+Exits with a non-zero status if any build failed or wasn't reproduced.
+`)
+
+func (opts *reportOpts) write(r report.Report) error {
+	var w io.Writer = stdout
+	if opts.outFile != "" {
+		f, err := fs.Create(opts.outFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := r.Write(w, opts.format); err != nil {
+		return err
+	}
+	if opts.outFile != "" {
+		opts.log("Report written to %s", opts.outFile)
+	}
+	return nil
+}
+
+func (opts *reportOpts) writeStepSummary(r report.Report) error {
+	if opts.stepSummary == "" {
+		return nil
+	}
+	opts.log("Writing GitHub Step Summary to %s", opts.stepSummary)
+	f, err := fs.Append(opts.stepSummary)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := r.Markdown(f); err != nil {
+		return fmt.Errorf("writing step summary: %w", err)
+	}
+	return nil
+}
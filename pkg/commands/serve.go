@@ -0,0 +1,134 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/internal/rebuilder"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	"github.com/hashicorp/composite-action-framework-go/pkg/cli"
+)
+
+type serveOpts struct {
+	buildFlags buildFlags
+	trust      trustOpts
+	addr       string
+	stateDir   string
+	workers    int
+}
+
+func (opts *serveOpts) Flags(fs *flag.FlagSet) {
+	opts.buildFlags.Flags(fs)
+	fs.StringVar(&opts.addr, "addr", "localhost:8080", "address to listen on")
+	fs.StringVar(&opts.stateDir, "state-dir", "rebuilder", "directory to keep jobs in, so they survive restarts")
+	fs.IntVar(&opts.workers, "workers", rebuilder.DefaultWorkers, "maximum number of jobs to run at once")
+	opts.trust.flags(fs)
+}
+
+func (opts *serveOpts) Init() error {
+	if err := opts.trust.load(); err != nil {
+		return err
+	}
+	// Jobs run the build instructions in the documents submitted, so anyone
+	// who can submit one can run commands on this machine.
+	if !opts.trust.requireSigned && !isLoopback(opts.addr) {
+		return fmt.Errorf("refusing to listen on %s without -require-signed, since anyone who can "+
+			"reach it could run commands on this machine by submitting build instructions", opts.addr)
+	}
+	return nil
+}
+
+// isLoopback returns true if addr only listens on a loopback interface.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+var Serve = cli.LeafCommand("serve", "run an http service which verifies builds submitted to it", func(opts *serveOpts) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	l := &opts.buildFlags.logOpts
+	svc, err := rebuilder.New(opts.stateDir, opts.verify,
+		rebuilder.WithWorkers(opts.workers),
+		rebuilder.WithLog(l.loudFunc()),
+		rebuilder.WithOpen(func(data []byte) ([]byte, error) {
+			return opts.trust.open(l, "submitted document", data)
+		}),
+	)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Addr:              opts.addr,
+		Handler:           svc.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	workersDone := make(chan struct{})
+	go func() {
+		svc.Run(ctx)
+		close(workersDone)
+	}()
+	go func() {
+		<-ctx.Done()
+		l.loud("Shutting down; running jobs will resume on restart.")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	l.loud("Listening on %s with %d workers; jobs are kept in %s", opts.addr, opts.workers, opts.stateDir)
+	err = srv.ListenAndServe()
+	stop()
+	<-workersDone
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}).WithHelp(`
+Run an independent rebuilder: an HTTP service which verifies builds submitted to it by
+running remote verification builds, on a pool of -workers workers.
+
+    POST /jobs              queue a build config or build result for verification
+    GET  /jobs              list every job
+    GET  /jobs/{id}         get a job's status
+    GET  /jobs/{id}/log     get a job's log
+    GET  /jobs/{id}/result  get a finished job's verification result
+
+Submitting a build config runs both a primary and a verification build from source,
+while submitting a build result runs only the verification build and compares it with
+that result. Jobs are kept in -state-dir, and jobs which were queued or running when
+the service stopped are run again when it restarts.
+
+Flags controlling remote builds, like -source-mode, apply to every job.
+
+Jobs run the build instructions in the documents submitted, so anyone who can submit a
+job can run commands on this machine. Submissions are checked with -trusted-key and
+-require-signed like any other document read, and serve refuses to listen on anything
+but a loopback address unless -require-signed is set.
+`)
+
+// verify runs a job, in the same way that the verify command verifies a
+// build config or build result file.
+func (opts *serveOpts) verify(ctx context.Context, in rebuilder.Input, w io.Writer) (*build.VerificationResult, error) {
+	return opts.buildFlags.verifyRemotely(ctx, in.Config, in.Result, w)
+}
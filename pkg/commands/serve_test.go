@@ -0,0 +1,47 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package commands
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/actions-go-build/internal/signing"
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+func TestServeOpts_Init(t *testing.T) {
+	dir := tmp.Dir(t)
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := filepath.Join(dir, "key.pub")
+	if err := signing.WriteKeyFiles(key, filepath.Join(dir, "key.pem"), pub); err != nil {
+		t.Fatal(err)
+	}
+	signed := trustOpts{keyFiles: []string{pub}, requireSigned: true}
+
+	cases := []struct {
+		addr    string
+		trust   trustOpts
+		wantErr bool
+	}{
+		{"localhost:8080", trustOpts{}, false},
+		{"127.0.0.1:8080", trustOpts{}, false},
+		{"[::1]:8080", trustOpts{}, false},
+		{":8080", trustOpts{}, true},
+		{"0.0.0.0:8080", trustOpts{}, true},
+		{"rebuilder.example:8080", trustOpts{keyFiles: []string{pub}}, true},
+		{":8080", signed, false},
+	}
+	for _, c := range cases {
+		opts := serveOpts{addr: c.addr, trust: c.trust}
+		if err := opts.Init(); (err != nil) != c.wantErr {
+			t.Errorf("Init with -addr %s and %+v: got error %v; want error: %t", c.addr, c.trust, err, c.wantErr)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/actions-go-build/internal/signing"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	"github.com/hashicorp/composite-action-framework-go/pkg/cli"
+	"github.com/hashicorp/composite-action-framework-go/pkg/json"
+)
+
+type signOpts struct {
+	logOpts
+	keyFile string
+	outFile string
+	path    string
+}
+
+func (opts *signOpts) Flags(fs *flag.FlagSet) {
+	opts.logOpts.Flags(fs)
+	fs.StringVar(&opts.keyFile, "key", "", "PEM file containing the ed25519 private key to sign with (required)")
+	fs.StringVar(&opts.outFile, "o", "", "write the signed document to this file instead of stdout")
+}
+
+func (opts *signOpts) Args(args *cli.ArgList) {
+	args.Required(&opts.path, "DOCUMENT")
+}
+
+var Sign = cli.LeafCommand("sign", "sign a build config, build result, or verification result", func(opts *signOpts) error {
+	if opts.keyFile == "" {
+		return fmt.Errorf("-key is required")
+	}
+	key, err := signing.ReadPrivateKeyFile(opts.keyFile)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(opts.path)
+	if err != nil {
+		return err
+	}
+	if signing.IsEnvelope(data) {
+		return fmt.Errorf("%s is already signed", opts.path)
+	}
+	kind, _, err := build.UpgradeDocument(data)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", opts.path, err)
+	}
+	envelope := signing.Sign(key, data)
+	if opts.outFile == "" {
+		return json.Write(stdout, envelope)
+	}
+	if err := json.WriteFile(opts.outFile, envelope); err != nil {
+		return err
+	}
+	opts.log("Signed %s written to %s", kind, opts.outFile)
+	return nil
+}).WithHelp(`
+Sign DOCUMENT, a build config, build result, or verification result, by wrapping it in a
+DSSE envelope signed with the ed25519 private key in -key. Create a key pair with:
+
+  openssl genpkey -algorithm ed25519 -out key.pem
+  openssl pkey -in key.pem -pubout -out key.pub
+
+Signed documents can be used anywhere build, verify, and inspect read a build config or
+result. Pass -trusted-key key.pub to check their signatures, and -require-signed to
+refuse documents that aren't signed with a trusted key.
+`)
@@ -0,0 +1,129 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package commands
+
+import (
+	"bytes"
+	_ "embed"
+	"flag"
+	"fmt"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/hashicorp/composite-action-framework-go/pkg/fs"
+	"github.com/hashicorp/composite-action-framework-go/pkg/json"
+)
+
+//go:embed templates/stepsummary.md.tmpl
+var verifyStepSummaryTemplate string
+
+//go:embed templates/buildsummary.md.tmpl
+var buildStepSummaryTemplate string
+
+// stepSummaryOpts writes a GitHub step summary, using either a built in
+// template or one supplied by the user.
+type stepSummaryOpts struct {
+	stepSummary  string
+	templateFile string
+}
+
+// flags adds the step summary flags, defaulting to defaultPath and
+// defaultTemplate. Each command passes its own defaults, since a template
+// written for one command's result won't render another's.
+func (opts *stepSummaryOpts) flags(fs *flag.FlagSet, defaultPath, defaultTemplate string) {
+	fs.StringVar(&opts.stepSummary, "github-step-summary", defaultPath, "write a github step summary to this file")
+	fs.StringVar(&opts.templateFile, "step-summary-template", defaultTemplate, "render the step summary using this go template file")
+}
+
+// write renders data using the user's template, or defaultTemplate if they
+// haven't supplied one, and appends it to the step summary file.
+func (opts *stepSummaryOpts) write(l *logOpts, defaultTemplate string, data any) error {
+	if opts.stepSummary == "" {
+		return nil
+	}
+	text, name := defaultTemplate, "step summary"
+	if opts.templateFile != "" {
+		b, err := os.ReadFile(opts.templateFile)
+		if err != nil {
+			return fmt.Errorf("reading step summary template: %w", err)
+		}
+		text, name = string(b), opts.templateFile
+	}
+	t, err := template.New(name).Funcs(stepSummaryFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+	// Render in full first, so a failing template doesn't leave half a
+	// summary behind.
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return err
+	}
+	l.log("Writing GitHub Step Summary to %s", opts.stepSummary)
+	f, err := fs.Append(opts.stepSummary)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = buf.WriteTo(f)
+	return err
+}
+
+// stepSummaryFuncs are available to every step summary template.
+var stepSummaryFuncs = template.FuncMap{
+	"json": func(a any) string {
+		s, err := json.String(a)
+		if err != nil {
+			return fmt.Sprintf("<error: %v>", err)
+		}
+		return s
+	},
+	"shortSHA": shortSHA,
+	"size":     humanSize,
+	"duration": humanDuration,
+}
+
+// shortSHA returns the first 8 characters of a hash or revision.
+func shortSHA(s string) string {
+	if len(s) > 8 {
+		return s[:8]
+	}
+	return s
+}
+
+// humanSize formats a number of bytes using binary units, e.g. 1.5 MiB.
+func humanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// humanDuration formats a duration, or a duration string as recorded in
+// build results, rounded to the nearest millisecond.
+func humanDuration(v any) (string, error) {
+	var d time.Duration
+	switch v := v.(type) {
+	case time.Duration:
+		d = v
+	case string:
+		if v == "" {
+			return "", nil
+		}
+		var err error
+		if d, err = time.ParseDuration(v); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("duration: unsupported type %T", v)
+	}
+	return d.Round(time.Millisecond).String(), nil
+}
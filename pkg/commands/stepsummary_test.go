@@ -0,0 +1,123 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package commands
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/actions-go-build/internal/testhelpers/buildtest"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	"github.com/hashicorp/actions-go-build/pkg/crt"
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+func testBuildResult() build.Result {
+	r := buildtest.Result()
+	r.Zip = crt.File{Name: "lockbox_1.2.3_linux_amd64.zip", Size: 1536, SHA256Sum: "0123456789abcdef"}
+	r.Meta.Duration = "1.23456s"
+	r.Steps = []build.StepResult{{Description: "running build instructions", Duration: "1.2s"}}
+	return r
+}
+
+func TestStepSummaryOpts_write(t *testing.T) {
+	cases := []struct {
+		desc, template string
+		data           any
+		want           []string
+	}{
+		{
+			"default build template",
+			"",
+			testBuildResult(),
+			[]string{
+				"## :white_check_mark: `lockbox` 1.2.3 linux/amd64 build succeeded in 1.235s.",
+				"| zip `lockbox_1.2.3_linux_amd64.zip` | 1.5 KiB | 0123456789abcdef |",
+				"| :white_check_mark: running build instructions | 1.2s |",
+			},
+		},
+		{
+			"custom template",
+			`{{ .Config.Product.Name }} {{ shortSHA .Zip.SHA256Sum }} {{ size .Zip.Size }} {{ duration .Meta.Duration }}`,
+			testBuildResult(),
+			[]string{"lockbox 01234567 1.5 KiB 1.235s"},
+		},
+		{
+			"custom verification template",
+			`{{ if .ReproducedCorrectly }}reproduced{{ end }} {{ .Primary.Config.Product.Name }}`,
+			&build.VerificationResult{Primary: &[]build.Result{testBuildResult()}[0], ReproducedCorrectly: true},
+			[]string{"reproduced lockbox"},
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.desc, func(t *testing.T) {
+			dir := tmp.Dir(t)
+			opts := stepSummaryOpts{stepSummary: filepath.Join(dir, "summary.md")}
+			if c.template != "" {
+				opts.templateFile = filepath.Join(dir, "summary.md.tmpl")
+				if err := os.WriteFile(opts.templateFile, []byte(c.template), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := opts.write(&logOpts{quietFlag: true}, buildStepSummaryTemplate, c.data); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(opts.stepSummary)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range c.want {
+				if !strings.Contains(string(got), want) {
+					t.Errorf("got summary:\n%s\nwant it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStepSummaryOpts_write_badTemplate(t *testing.T) {
+	dir := tmp.Dir(t)
+	opts := stepSummaryOpts{
+		stepSummary:  filepath.Join(dir, "summary.md"),
+		templateFile: filepath.Join(dir, "summary.md.tmpl"),
+	}
+	if err := os.WriteFile(opts.templateFile, []byte(`{{ .NoSuchField }}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.write(&logOpts{quietFlag: true}, "", testBuildResult()); err == nil {
+		t.Fatal("got nil error; want an error")
+	}
+	if _, err := os.Stat(opts.stepSummary); !os.IsNotExist(err) {
+		t.Errorf("got step summary written; want nothing written after a template error")
+	}
+}
+
+// TestStepSummaryOpts_flags checks STEP_SUMMARY_TEMPLATE, which is written
+// for verification results, is only used by verify.
+func TestStepSummaryOpts_flags(t *testing.T) {
+	t.Setenv("STEP_SUMMARY_TEMPLATE", "summary.md.tmpl")
+	for _, c := range []struct {
+		desc string
+		opts interface {
+			Flags(*flag.FlagSet)
+		}
+		want string
+	}{
+		{"build", &buildOpts{}, ""},
+		{"verify", &verifyOpts{}, "summary.md.tmpl"},
+	} {
+		fs := flag.NewFlagSet(c.desc, flag.ContinueOnError)
+		c.opts.Flags(fs)
+		if err := fs.Parse(nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := fs.Lookup("step-summary-template").Value.String(); got != c.want {
+			t.Errorf("%s: got template %q; want %q", c.desc, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package commands
+
+import (
+	"crypto/ed25519"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/actions-go-build/internal/signing"
+	"github.com/hashicorp/actions-go-build/internal/tlog"
+	"github.com/hashicorp/composite-action-framework-go/pkg/cli"
+	"github.com/hashicorp/composite-action-framework-go/pkg/json"
+)
+
+// defaultTLogDir is where the transparency log is kept by default.
+const defaultTLogDir = "tlog"
+
+// tlogOpts are the options of commands which read or write a log.
+type tlogOpts struct {
+	logOpts
+	dir     string
+	outFile string
+}
+
+func (opts *tlogOpts) Flags(fs *flag.FlagSet) {
+	opts.logOpts.Flags(fs)
+	fs.StringVar(&opts.dir, "tlog", defaultTLogDir, "directory the transparency log is kept in")
+	fs.StringVar(&opts.outFile, "o", "", "write the json output to this file instead of stdout")
+}
+
+func (opts *tlogOpts) write(v any) error {
+	if opts.outFile == "" {
+		return json.Write(stdout, v)
+	}
+	if err := json.WriteFile(opts.outFile, v); err != nil {
+		return err
+	}
+	opts.log("Written to %s", opts.outFile)
+	return nil
+}
+
+// tlogVerifyOpts are the options of commands which check proofs offline.
+type tlogVerifyOpts struct {
+	logOpts
+	publicKey string
+}
+
+func (opts *tlogVerifyOpts) Flags(fs *flag.FlagSet) {
+	opts.logOpts.Flags(fs)
+	fs.StringVar(&opts.publicKey, "public-key", "", "PEM file containing the log's public key (required)")
+}
+
+func (opts *tlogVerifyOpts) key() (ed25519.PublicKey, error) {
+	if opts.publicKey == "" {
+		return nil, fmt.Errorf("-public-key is required")
+	}
+	return signing.ReadPublicKeyFile(opts.publicKey)
+}
+
+// TLog groups the transparency log commands.
+var TLog = cli.RootCommand("tlog", "keep an append-only transparency log of verification results",
+	TLogAppend, TLogHead, TLogProveInclusion, TLogProveConsistency, TLogVerifyInclusion, TLogVerifyConsistency)
+
+type tlogAppendOpts struct {
+	tlogOpts
+	trust trustOpts
+	paths []string
+}
+
+func (opts *tlogAppendOpts) Flags(fs *flag.FlagSet) {
+	opts.tlogOpts.Flags(fs)
+	opts.trust.flags(fs)
+}
+
+func (opts *tlogAppendOpts) Init() error { return opts.trust.load() }
+
+func (opts *tlogAppendOpts) Args(args *cli.ArgList) {
+	args.RequiredVariadic(&opts.paths, "RESULT", 1)
+}
+
+var TLogAppend = cli.LeafCommand("append", "append verification results to the log", func(opts *tlogAppendOpts) error {
+	entries := make([][]byte, len(opts.paths))
+	for i, path := range opts.paths {
+		var err error
+		if entries[i], err = os.ReadFile(path); err != nil {
+			return err
+		}
+		// Signed results are appended as they are, so only check them.
+		if _, err := opts.trust.open(&opts.logOpts, path, entries[i]); err != nil {
+			return err
+		}
+	}
+	l, err := tlog.Open(opts.dir)
+	if err != nil {
+		return err
+	}
+	index, head, err := l.AppendEntries(entries...)
+	if err != nil {
+		return err
+	}
+	for i, path := range opts.paths {
+		opts.log("Appended %s at index %d", path, index+i)
+	}
+	return opts.write(head)
+}).WithHelp(`
+Append each RESULT, a verification result file, to the transparency log, and print the
+signed tree head of the log afterwards. Each file is stored in the log byte for byte, and
+only that exact file can later be proven to be in the log. Signed results are checked with
+-trusted-key and -require-signed, and stored with their signatures.
+
+The log is created in the -tlog directory if it doesn't exist, along with a new ed25519
+signing key. Give auditors the public key, ` + tlog.PublicKeyFile + `, so they can check
+proofs from the log. Keep the directory private: anyone with key.pem can sign tree heads.
+`)
+
+var TLogHead = cli.LeafCommand("head", "print the log's latest signed tree head", func(opts *tlogOpts) error {
+	l, err := tlog.Open(opts.dir)
+	if err != nil {
+		return err
+	}
+	head, err := l.Head()
+	if err != nil {
+		return err
+	}
+	return opts.write(head)
+})
+
+type tlogProveInclusionOpts struct {
+	tlogOpts
+	path string
+}
+
+func (opts *tlogProveInclusionOpts) Args(args *cli.ArgList) {
+	args.Required(&opts.path, "RESULT")
+}
+
+var TLogProveInclusion = cli.LeafCommand("prove-inclusion", "prove a verification result is in the log", func(opts *tlogProveInclusionOpts) error {
+	entry, err := os.ReadFile(opts.path)
+	if err != nil {
+		return err
+	}
+	l, err := tlog.Open(opts.dir)
+	if err != nil {
+		return err
+	}
+	proof, err := l.ProveInclusion(entry)
+	if err != nil {
+		return err
+	}
+	return opts.write(proof)
+}).WithHelp(`
+Print a proof that RESULT, a verification result file, is in the log as of its latest
+tree head. RESULT must be byte for byte the file that was appended; each entry is kept in
+the log's entries directory, named by its index. The proof can be checked offline with
+verify-inclusion.
+`)
+
+type tlogProveConsistencyOpts struct {
+	tlogOpts
+	size string
+}
+
+func (opts *tlogProveConsistencyOpts) Args(args *cli.ArgList) {
+	args.Required(&opts.size, "SIZE")
+}
+
+var TLogProveConsistency = cli.LeafCommand("prove-consistency", "prove the log was only appended to", func(opts *tlogProveConsistencyOpts) error {
+	size, err := strconv.Atoi(opts.size)
+	if err != nil {
+		return fmt.Errorf("invalid size %q: %w", opts.size, err)
+	}
+	l, err := tlog.Open(opts.dir)
+	if err != nil {
+		return err
+	}
+	proof, err := l.ProveConsistency(size)
+	if err != nil {
+		return err
+	}
+	return opts.write(proof)
+}).WithHelp(`
+Print a proof that the log as of its tree head of size SIZE is a prefix of the log as of
+its latest tree head, i.e. that no results were changed or removed in between. The proof
+can be checked offline with verify-consistency.
+`)
+
+type tlogVerifyInclusionOpts struct {
+	tlogVerifyOpts
+	proof, result string
+}
+
+func (opts *tlogVerifyInclusionOpts) Args(args *cli.ArgList) {
+	args.Required(&opts.proof, "PROOF")
+	args.Required(&opts.result, "RESULT")
+}
+
+var TLogVerifyInclusion = cli.LeafCommand("verify-inclusion", "check an inclusion proof offline", func(opts *tlogVerifyInclusionOpts) error {
+	pub, err := opts.key()
+	if err != nil {
+		return err
+	}
+	proof, err := json.ReadFile[tlog.InclusionProof](opts.proof)
+	if err != nil {
+		return err
+	}
+	entry, err := os.ReadFile(opts.result)
+	if err != nil {
+		return err
+	}
+	if err := proof.Verify(pub, entry); err != nil {
+		return err
+	}
+	opts.log("%s is at index %d of the log as of its tree head of size %d, signed at %s",
+		opts.result, proof.LeafIndex, proof.TreeHead.Size, proof.TreeHead.Timestamp.Format(time.RFC3339))
+	return nil
+}).WithHelp(`
+Check that PROOF, written by prove-inclusion, proves RESULT is in the log whose tree heads
+are signed with the key in -public-key. RESULT must be byte for byte the file the proof
+was made for. Doesn't need access to the log.
+`)
+
+type tlogVerifyConsistencyOpts struct {
+	tlogVerifyOpts
+	proof string
+}
+
+func (opts *tlogVerifyConsistencyOpts) Args(args *cli.ArgList) {
+	args.Required(&opts.proof, "PROOF")
+}
+
+var TLogVerifyConsistency = cli.LeafCommand("verify-consistency", "check a consistency proof offline", func(opts *tlogVerifyConsistencyOpts) error {
+	pub, err := opts.key()
+	if err != nil {
+		return err
+	}
+	proof, err := json.ReadFile[tlog.ConsistencyProof](opts.proof)
+	if err != nil {
+		return err
+	}
+	if err := proof.Verify(pub); err != nil {
+		return err
+	}
+	opts.log("The log of size %d extends the log of size %d", proof.New.Size, proof.Old.Size)
+	return nil
+}).WithHelp(`
+Check that PROOF, written by prove-consistency, proves the log was only appended to
+between its two tree heads, which must both be signed with the key in -public-key.
+Doesn't need access to the log.
+`)
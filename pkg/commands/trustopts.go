@@ -0,0 +1,53 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package commands
+
+import (
+	"crypto/ed25519"
+	"flag"
+	"fmt"
+
+	"github.com/hashicorp/actions-go-build/internal/signing"
+)
+
+// trustOpts control how signatures on the documents a command reads are
+// checked.
+type trustOpts struct {
+	keyFiles      []string
+	requireSigned bool
+	keys          []ed25519.PublicKey
+}
+
+func (opts *trustOpts) flags(fs *flag.FlagSet) {
+	fs.Func("trusted-key", "PEM file containing an ed25519 public key to trust signatures from (repeatable)", func(s string) error {
+		opts.keyFiles = append(opts.keyFiles, s)
+		return nil
+	})
+	fs.BoolVar(&opts.requireSigned, "require-signed", false, "fail unless documents read are signed with a -trusted-key")
+}
+
+func (opts *trustOpts) load() error {
+	if opts.requireSigned && len(opts.keyFiles) == 0 {
+		return fmt.Errorf("-require-signed needs at least one -trusted-key")
+	}
+	opts.keys = make([]ed25519.PublicKey, len(opts.keyFiles))
+	for i, path := range opts.keyFiles {
+		var err error
+		if opts.keys[i], err = signing.ReadPublicKeyFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trust returns how documents are trusted, logging to l.
+func (opts *trustOpts) trust(l *logOpts) signing.Trust {
+	return signing.Trust{Keys: opts.keys, RequireSigned: opts.requireSigned, Log: l.logFunc(), Warn: l.loudFunc()}
+}
+
+// open returns the document in data, read from location, as described by
+// signing.Trust.Open.
+func (opts *trustOpts) open(l *logOpts, location string, data []byte) ([]byte, error) {
+	return opts.trust(l).Open(location, data)
+}
@@ -0,0 +1,76 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package commands
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/actions-go-build/internal/signing"
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+func TestTrustOpts_open(t *testing.T) {
+	dir := tmp.Dir(t)
+	var keys [2]ed25519.PrivateKey
+	var pubFiles [2]string
+	for i := range keys {
+		var err error
+		if _, keys[i], err = ed25519.GenerateKey(rand.Reader); err != nil {
+			t.Fatal(err)
+		}
+		pubFiles[i] = filepath.Join(dir, fmt.Sprintf("%d.pub", i))
+		if err := signing.WriteKeyFiles(keys[i], filepath.Join(dir, fmt.Sprintf("%d.pem", i)), pubFiles[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	doc := []byte(`{"Kind": "BuildResult"}`)
+	signed, err := json.Marshal(signing.Sign(keys[0], doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		desc    string
+		opts    trustOpts
+		data    []byte
+		wantErr bool
+	}{
+		{"unsigned", trustOpts{}, doc, false},
+		{"signed, unchecked", trustOpts{}, signed, false},
+		{"signed, trusted", trustOpts{keyFiles: pubFiles[:1]}, signed, false},
+		{"signed, one of several trusted", trustOpts{keyFiles: pubFiles[:]}, signed, false},
+		{"signed, untrusted", trustOpts{keyFiles: pubFiles[1:]}, signed, true},
+		{"signed, required", trustOpts{keyFiles: pubFiles[:1], requireSigned: true}, signed, false},
+		{"unsigned, required", trustOpts{keyFiles: pubFiles[:1], requireSigned: true}, doc, true},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			if err := c.opts.load(); err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.opts.open(&logOpts{}, "doc.json", c.data)
+			if c.wantErr {
+				if err == nil {
+					t.Fatal("got nil error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(doc) {
+				t.Errorf("got %q; want %q", got, doc)
+			}
+		})
+	}
+
+	if err := (&trustOpts{requireSigned: true}).load(); err == nil {
+		t.Error("got nil error requiring signatures without trusted keys")
+	}
+}
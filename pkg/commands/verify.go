@@ -4,62 +4,95 @@
 package commands
 
 import (
-	_ "embed"
 	"flag"
-	"fmt"
 	"os"
-	"text/template"
+	"path/filepath"
+	"strings"
 
+	"github.com/hashicorp/actions-go-build/internal/junit"
+	"github.com/hashicorp/actions-go-build/internal/tlog"
+	"github.com/hashicorp/actions-go-build/pkg/build"
 	"github.com/hashicorp/composite-action-framework-go/pkg/cli"
-	"github.com/hashicorp/composite-action-framework-go/pkg/fs"
-	"github.com/hashicorp/composite-action-framework-go/pkg/json"
 )
 
-//go:embed templates/stepsummary.md.tmpl
-var stepSummaryTemplate string
-
 type verifyOpts struct {
 	verifyish
-	outFile     string
-	stepSummary string
+	stepSummaryOpts
+	outFile   string
+	junitFile string
+	tlogDir   string
 }
 
 func (opts *verifyOpts) Flags(fs *flag.FlagSet) {
 	opts.verifyish.Flags(fs)
-	fs.StringVar(&opts.outFile, "o", "", "write the result json to this file")
-	fs.StringVar(&opts.stepSummary, "github-step-summary", os.Getenv("GITHUB_STEP_SUMMARY"), "write a github step summary to this file")
+	fs.StringVar(&opts.outFile, "o", "", "write the result json to this file, or for several products, to this file with each product name inserted before its extension")
+	opts.stepSummaryOpts.flags(fs, os.Getenv("GITHUB_STEP_SUMMARY"), os.Getenv("STEP_SUMMARY_TEMPLATE"))
+	fs.StringVar(&opts.junitFile, "junit", "", "write a junit xml report to this file")
+	fs.StringVar(&opts.tlogDir, "tlog", "", "append the results to the transparency log in this directory")
 }
 
 var Verify = cli.LeafCommand("verify", "verify a build's reproducibility", func(opts *verifyOpts) error {
-	result, err := opts.runVerification()
-	if err != nil {
-		return err
-	}
-	if opts.outFile != "" {
-		if err := json.WriteFile(opts.outFile, result); err != nil {
+	var results []*build.VerificationResult
+	err := opts.forEachProduct(func(v *verifyish) error {
+		result, err := v.runVerification()
+		if err != nil {
 			return err
 		}
-		opts.log("Result written to %s", opts.outFile)
-	}
-	if opts.stepSummary != "" {
-		opts.log("Writing GitHub Step Summary to %s", opts.stepSummary)
-		f, err := fs.Append(opts.stepSummary)
-		if err != nil {
+		results = append(results, result)
+		if err := opts.stepSummaryOpts.write(&opts.logOpts, verifyStepSummaryTemplate, result); err != nil {
 			return err
 		}
-		defer f.Close()
-		funcs := template.FuncMap{
-			"json": func(a any) string {
-				s, err := json.String(a)
-				if err != nil {
-					return fmt.Sprintf("<error: %v>", err)
-				}
-				return s
-			},
+		if opts.outFile != "" {
+			path := opts.outFile
+			if v.product != nil {
+				path = productOutFile(path, v.product.DisplayName())
+			}
+			if err := build.WriteDocumentFile(path, result); err != nil {
+				return err
+			}
+			opts.log("Result written to %s", path)
+		}
+		return v.output.result("Reproducibility verification", result)
+	})
+	if opts.junitFile != "" && len(results) != 0 {
+		suites := make([]junit.TestSuite, len(results))
+		for i, r := range results {
+			suites[i] = junit.ForVerification(*r)
 		}
-		if err := template.Must(template.New("").Funcs(funcs).Parse(stepSummaryTemplate)).Execute(f, result); err != nil {
+		if err := junit.WriteFile(opts.junitFile, "verify", suites...); err != nil {
+			return err
+		}
+		opts.log("JUnit report written to %s", opts.junitFile)
+	}
+	if opts.tlogDir != "" && len(results) != 0 {
+		if err := opts.appendToTLog(results); err != nil {
 			return err
 		}
 	}
-	return opts.output.result("Reproducibility verification", result)
+	return err
 })
+
+// productOutFile returns where the result for one of several products is
+// written: outFile with the product name inserted before its extension, so
+// that each file is a single document, e.g. result.lockbox.json.
+func productOutFile(outFile, product string) string {
+	ext := filepath.Ext(outFile)
+	return strings.TrimSuffix(outFile, ext) + "." + product + ext
+}
+
+func (opts *verifyOpts) appendToTLog(results []*build.VerificationResult) error {
+	l, err := tlog.Open(opts.tlogDir)
+	if err != nil {
+		return err
+	}
+	rs := make([]build.VerificationResult, len(results))
+	for i, r := range results {
+		rs[i] = *r
+	}
+	index, head, err := l.Append(rs...)
+	if err != nil {
+		return err
+	}
+	opts.log("Appended %d result(s) to the transparency log at index %d; its root hash is now %s", len(rs), index, head.RootHash)
+	return nil
+}
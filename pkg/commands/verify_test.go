@@ -0,0 +1,78 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/actions-go-build/internal/config"
+	"github.com/hashicorp/actions-go-build/pkg/build"
+	"github.com/hashicorp/composite-action-framework-go/pkg/git"
+	tmp "github.com/hashicorp/composite-action-framework-go/pkg/testhelpers/tmptest"
+)
+
+// TestVerify_products verifies a repository containing two products which
+// share a single go.mod at the repository root.
+func TestVerify_products(t *testing.T) {
+	t.Setenv("TMPDIR", tmp.Dir(t))
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+	root := tmp.Dir(t)
+	for name, contents := range map[string]string{
+		config.ProductsFile:       `{"Products": [{"Dir": "cmd/a"}, {"Dir": "cmd/b"}]}`,
+		"go.mod":                  "module example.com/tools\n\ngo 1.24\n",
+		"internal/greet/greet.go": "package greet\n\nconst Hello = \"hello\"\n",
+		"cmd/a/main.go":           "package main\n\nimport \"example.com/tools/internal/greet\"\n\nfunc main() { println(greet.Hello, \"a\") }\n",
+		"cmd/a/VERSION":           "1.0.0",
+		"cmd/b/main.go":           "package main\n\nimport \"example.com/tools/internal/greet\"\n\nfunc main() { println(greet.Hello, \"b\") }\n",
+		"cmd/b/VERSION":           "2.0.0",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo, err := git.Init(root, git.WithAuthor("test", "test@test.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Add("."); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Commit("initial commit"); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(tmp.Dir(t), "results.json")
+	if err := Verify.Execute([]string{"", "-staggertime", "0", "-o", out, root}); err != nil {
+		t.Fatal(err)
+	}
+	var results []build.VerificationResult
+	for _, name := range []string{"results.a.json", "results.b.json"} {
+		r, err := build.ReadDocumentFile[build.VerificationResult](filepath.Join(filepath.Dir(out), name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, r)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results; want 2", len(results))
+	}
+	for i, want := range []string{"cmd/a", "cmd/b"} {
+		r := results[i]
+		if !r.ReproducedCorrectly {
+			t.Errorf("product %s: got a non-reproducible result", want)
+		}
+		if got := r.Primary.Config.Parameters.ProductDir; got != want {
+			t.Errorf("got product dir %q; want %q", got, want)
+		}
+		if got, want := r.Primary.Config.Paths.WorkDir, filepath.Join(root, want); got != want {
+			t.Errorf("got primary work dir %q; want %q", got, want)
+		}
+	}
+}
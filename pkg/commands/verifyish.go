@@ -6,10 +6,11 @@ package commands
 import (
 	"flag"
 	"fmt"
+	"os"
 	"time"
 
+	"github.com/hashicorp/actions-go-build/internal/config"
 	"github.com/hashicorp/actions-go-build/pkg/build"
-	"github.com/hashicorp/composite-action-framework-go/pkg/json"
 )
 
 // A verifyish represents something that can be verified as reproducible.
@@ -37,6 +38,10 @@ type verifyish struct {
 
 	primary      build.ResultSource
 	verification build.ResultSource
+
+	// products are declared in the target directory, if it contains
+	// several products.
+	products []config.ProductSpec
 }
 
 func (v *verifyish) Flags(fs *flag.FlagSet) {
@@ -49,9 +54,36 @@ func (v *verifyish) Init() error {
 	if err := v.buildish.Init(); err != nil {
 		return err
 	}
+	var err error
+	if v.products, err = v.declaredProducts(); err != nil {
+		return err
+	}
+	if len(v.products) != 0 {
+		if v.verificationBuildResultFile != "" {
+			return fmt.Errorf("-verification-build-result can't be used with multiple products")
+		}
+		// Result sources are set for each product in forEachProduct.
+		return nil
+	}
 	return v.setResultSources()
 }
 
+// forEachProduct calls fn once for each product declared in the target
+// directory, or just once for the target itself if it doesn't declare any.
+func (v *verifyish) forEachProduct(fn func(*verifyish) error) error {
+	if len(v.products) == 0 {
+		return fn(v)
+	}
+	return eachProduct(&v.logOpts, v.products, func(p config.ProductSpec) error {
+		pv := *v
+		pv.buildish.product = &p
+		if err := pv.setResultSources(); err != nil {
+			return err
+		}
+		return fn(&pv)
+	})
+}
+
 func (v *verifyish) runVerification() (*build.VerificationResult, error) {
 	verifier, err := v.buildish.buildFlags.newVerifier(v.primary, v.verification)
 	if err != nil {
@@ -93,7 +125,18 @@ func (v *verifyish) verificationResultSource() (build.ResultSource, error) {
 }
 
 func (v *verifyish) verificationResultSourceFromFile(path string) (build.ResultSource, error) {
-	return json.ReadFile[build.Result](path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = v.buildish.trust.open(&v.logOpts, path, data); err != nil {
+		return nil, err
+	}
+	r, err := build.ReadDocument[build.Result](data)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return r, nil
 }
 
 func (v *verifyish) verificationResultSourceFromNewBuild() (build.ResultSource, error) {
@@ -8,16 +8,23 @@ import (
 )
 
 type FileSetHashes struct {
-	Bin      FileHashes
-	Zip      FileHashes
+	Bin FileHashes
+	Zip FileHashes
+	// Extra contains hashes of any optional artifacts, e.g. OCI images.
+	Extra    []FileHashes `json:",omitempty"`
 	AllMatch bool
 }
 
-func NewFileSetHashes(bin, zip FileHashes) FileSetHashes {
+func NewFileSetHashes(bin, zip FileHashes, extra ...FileHashes) FileSetHashes {
+	allMatch := !bin.mismatch() && !zip.mismatch()
+	for _, e := range extra {
+		allMatch = allMatch && !e.mismatch()
+	}
 	return FileSetHashes{
 		Bin:      bin,
 		Zip:      zip,
-		AllMatch: !bin.mismatch() && !zip.mismatch(),
+		Extra:    extra,
+		AllMatch: allMatch,
 	}
 }
 
@@ -28,5 +35,10 @@ func (fsh FileSetHashes) Error() error {
 	if fsh.Zip.mismatch() {
 		return fmt.Errorf("zip file mismatch")
 	}
+	for _, e := range fsh.Extra {
+		if e.mismatch() {
+			return fmt.Errorf("%s mismatch", e.Description)
+		}
+	}
 	return nil
 }
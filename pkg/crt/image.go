@@ -0,0 +1,14 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package crt
+
+// Image is an OCI image layout produced during the build.
+type Image struct {
+	File
+	// Ref is the name:tag reference the image is tagged with.
+	Ref string
+	// ManifestDigest is the digest of the image manifest. This is the
+	// canonical identity of the image, so it's what we compare when verifying.
+	ManifestDigest string
+}
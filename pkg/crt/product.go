@@ -48,7 +48,12 @@ type Product struct {
 	// dirty, or else it's a SHA1 hash of the HEAD commit plus all the contents
 	// of all dirty files.
 	SourceHash string
-	DirtyFiles []string `json:",omitempty"`
+	// SourceTreeHash is the git tree hash of the repository's root directory
+	// at Revision. Remote builds check that the source code they download
+	// has the same tree hash, so they're sure to build the right code.
+	// It isn't part of config IDs.
+	SourceTreeHash string   `json:",omitempty"`
+	DirtyFiles     []string `json:",omitempty"`
 }
 
 func (p Product) IsDirty() bool {
@@ -123,6 +128,7 @@ func (p Product) setDefaults(rc RepoContext) (Product, error) {
 	p.Revision = rc.CommitSHA
 	p.RevisionTime = rc.CommitTime.UTC().Format(time.RFC3339)
 	p.SourceHash = rc.SourceHash
+	p.SourceTreeHash = rc.TreeHash
 	p.DirtyFiles = rc.DirtyFiles
 	return p, nil
 }
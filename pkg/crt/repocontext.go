@@ -9,10 +9,13 @@ import (
 	"io/ioutil"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/hashicorp/composite-action-framework-go/pkg/fs"
 	"github.com/hashicorp/composite-action-framework-go/pkg/git"
 	"github.com/hashicorp/go-version"
@@ -20,15 +23,19 @@ import (
 )
 
 type RepoContext struct {
-	RepoName    string
-	ModuleName  string
-	Dir         string
-	RootDir     string
-	CommitSHA   string
+	RepoName   string
+	ModuleName string
+	Dir        string
+	RootDir    string
+	CommitSHA  string
+	// TreeHash is the hash of the root tree of the commit at CommitSHA.
+	TreeHash    string
 	CommitTime  time.Time
 	CoreVersion version.Version
-	SourceHash  string
-	DirtyFiles  []string `json:",omitempty"`
+	// VersionSource records where CoreVersion came from.
+	VersionSource VersionSource
+	SourceHash    string
+	DirtyFiles    []string `json:",omitempty"`
 }
 
 // IsDirty returns true if the worktree is dirty, ignoring
@@ -37,8 +44,45 @@ func (rc RepoContext) IsDirty() bool {
 	return rc.SourceHash == rc.CommitSHA
 }
 
+// RepoContextOption configures GetRepoContext.
+type RepoContextOption func(*repoContextSettings)
+
+type repoContextSettings struct {
+	versionStrategy VersionStrategy
+	skipCoreVersion bool
+	productDirs     []string
+}
+
+// WithVersionStrategy sets how the core version is determined. The default
+// is VersionFileSearch.
+func WithVersionStrategy(s VersionStrategy) RepoContextOption {
+	return func(rcs *repoContextSettings) { rcs.versionStrategy = s }
+}
+
+// WithoutCoreVersion doesn't look up the core version at all, for when the
+// full product version is already known. Strategies like git tags can fail
+// where the version isn't needed, e.g. in shallow clones.
+func WithoutCoreVersion() RepoContextOption {
+	return func(rcs *repoContextSettings) { rcs.skipCoreVersion = true }
+}
+
+// WithProductDirs is for repositories containing several products, where
+// dirs are their directories relative to the repository root, and the
+// directory passed to GetRepoContext is one of them. Changes anywhere in the
+// repository make it dirty, since the whole repository is built, except in
+// the build output directories inside any of dirs. Its go.mod may be in a
+// parent directory.
+func WithProductDirs(dirs ...string) RepoContextOption {
+	return func(rcs *repoContextSettings) { rcs.productDirs = dirs }
+}
+
 // GetRepoContext reads the repository context from the directory specified.
-func GetRepoContext(dir string, ignoreDirs []string) (RepoContext, error) {
+func GetRepoContext(dir string, ignoreDirs []string, opts ...RepoContextOption) (RepoContext, error) {
+	settings := repoContextSettings{versionStrategy: VersionFileSearch{}}
+	for _, o := range opts {
+		o(&settings)
+	}
+
 	repoName, err := getRepoName(dir)
 	if err != nil {
 		return RepoContext{}, err
@@ -57,19 +101,40 @@ func GetRepoContext(dir string, ignoreDirs []string) (RepoContext, error) {
 	sha := commits[0].ID
 	ts := commits[0].AuthorTime
 
-	v, err := getCoreVersion(dir)
+	treeHash, err := getTreeHash(dir, sha)
 	if err != nil {
 		return RepoContext{}, err
 	}
 
+	var (
+		coreVersion   version.Version
+		versionSource VersionSource
+	)
+	if !settings.skipCoreVersion {
+		v, src, err := settings.versionStrategy.CoreVersion(dir)
+		if err != nil {
+			return RepoContext{}, maybeErr(err, "getting version using %s strategy", src.Strategy)
+		}
+		coreVersion, versionSource = *v, src
+	}
+
+	if settings.productDirs != nil {
+		var ignore []string
+		for _, pd := range settings.productDirs {
+			for _, d := range ignoreDirs {
+				ignore = append(ignore, path.Join(pd, d))
+			}
+		}
+		ignoreDirs = ignore
+	}
+
 	worktreeState, err := WorktreeStateFunc(dir, ignoreDirs)
 	if err != nil {
 		return RepoContext{}, err // blah
 	}
 
 	var moduleName string
-	goDotMod := filepath.Join(dir, "go.mod")
-	exists, err := fs.FileExists(goDotMod)
+	goDotMod, exists, err := findGoMod(dir, repo.RootDir(), settings.productDirs != nil)
 	if err != nil {
 		return RepoContext{}, err
 	}
@@ -86,15 +151,17 @@ func GetRepoContext(dir string, ignoreDirs []string) (RepoContext, error) {
 	}
 
 	return RepoContext{
-		RepoName:    repoName,
-		ModuleName:  moduleName,
-		Dir:         dir,
-		RootDir:     repo.RootDir(),
-		CommitSHA:   sha,
-		CommitTime:  ts,
-		CoreVersion: *v,
-		SourceHash:  worktreeState.SourceHash,
-		DirtyFiles:  worktreeState.DirtyFiles,
+		RepoName:      repoName,
+		ModuleName:    moduleName,
+		Dir:           dir,
+		RootDir:       repo.RootDir(),
+		CommitSHA:     sha,
+		TreeHash:      treeHash,
+		CommitTime:    ts,
+		CoreVersion:   coreVersion,
+		VersionSource: versionSource,
+		SourceHash:    worktreeState.SourceHash,
+		DirtyFiles:    worktreeState.DirtyFiles,
 	}, nil
 }
 
@@ -120,6 +187,44 @@ func getWorktreeState(dir string, ignoreDirs []string) (*git.WorktreeState, erro
 	return &s, nil
 }
 
+// findGoMod returns the path to the go.mod for dir. Products in a
+// repository containing several may share a go.mod in a parent directory,
+// so if searchUp is set, parents are searched up to the repository root.
+func findGoMod(dir, root string, searchUp bool) (string, bool, error) {
+	rel := "."
+	if searchUp {
+		var err error
+		if rel, err = relPath(root, dir); err != nil {
+			return "", false, err
+		}
+	}
+	for {
+		goDotMod := filepath.Join(dir, "go.mod")
+		exists, err := fs.FileExists(goDotMod)
+		if err != nil || exists || rel == "." {
+			return goDotMod, exists, err
+		}
+		dir, rel = filepath.Dir(dir), path.Dir(rel)
+	}
+}
+
+// relPath returns the slash-separated path of dir relative to root,
+// resolving any symlinks in either.
+func relPath(root, dir string) (string, error) {
+	var err error
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", err
+	}
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
 func makeIgnorePatterns(dirNames []string) []string {
 	for i, d := range dirNames {
 		dirNames[i] = fmt.Sprintf("^%s\\/", d)
@@ -127,6 +232,19 @@ func makeIgnorePatterns(dirNames []string) []string {
 	return dirNames
 }
 
+// getTreeHash returns the root tree hash of the commit sha.
+func getTreeHash(dir, sha string) (string, error) {
+	repo, err := gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", err
+	}
+	c, err := repo.CommitObject(plumbing.NewHash(sha))
+	if err != nil {
+		return "", err
+	}
+	return c.TreeHash.String(), nil
+}
+
 func getRepoName(dir string) (string, error) {
 	var repoName string
 	if repoName = os.Getenv("PRODUCT_REPOSITORY"); repoName != "" {
@@ -5,6 +5,10 @@ package crt
 
 import (
 	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 func TestGetRepoNameFromRemoteURL(t *testing.T) {
@@ -69,3 +73,34 @@ func TestGetRepoNameFromRemoteURL(t *testing.T) {
 		})
 	}
 }
+
+func TestGetRepoContext_withoutCoreVersion(t *testing.T) {
+	t.Setenv("PRODUCT_REPOSITORY", "dadgarcorp/lockbox")
+	dir := writeTmpFileTree(t, nil)
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Commit("commit", &gogit.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            &object.Signature{Name: "test", Email: "test@test.com", When: time.Now()},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// There are no tags, as in a shallow clone.
+	if _, err := GetRepoContext(dir, nil, WithVersionStrategy(GitTag{})); err == nil {
+		t.Fatal("got nil error looking up the core version without any tags")
+	}
+	rc, err := GetRepoContext(dir, nil, WithVersionStrategy(GitTag{}), WithoutCoreVersion())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rc.CoreVersion.String(); got != "" {
+		t.Errorf("got core version %q; want none", got)
+	}
+}
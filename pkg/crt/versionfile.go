@@ -18,13 +18,6 @@ const defaultVersionString = "0.0.0-version-file-missing"
 
 var defaultVersion = version.Must(version.NewVersion(defaultVersionString))
 
-// getCoreVersion exists so that we can add additional version strategies
-// in the future. Currently we're only adding a single strategy, which is
-// to read from a VERSION file.
-func getCoreVersion(dir string) (*version.Version, error) {
-	return getCoreVersionFromVersionFile(dir)
-}
-
 var versionSearchPath = []string{".", ".release", "version", "dev"}
 
 func versionSearchPaths(basedir string) []string {
@@ -60,21 +53,31 @@ func getVersionFile(dir string) (string, error) {
 	return versionFile, nil
 }
 
-func getCoreVersionFromVersionFile(dir string) (*version.Version, error) {
+// searchVersionFile returns the version in the first VERSION file found in
+// versionSearchPath, and the path to that file relative to dir. If there
+// isn't one, it returns the default version and an empty path.
+func searchVersionFile(dir string) (*version.Version, string, error) {
 	versionFile, err := getVersionFile(dir)
 	if err != nil {
 		// Just warn for now; we may make this a hard requirement in the future.
 		log.Printf("WARNING: No VERSION file found in  any of %s: %v; "+
 			"using %s as the default if the version input isn't set.",
 			strings.Join(versionSearchPath, ", "), err, defaultVersion)
-		return defaultVersion, nil
+		return defaultVersion, "", nil
 	}
-	b, err := ioutil.ReadFile(versionFile)
+	rel := strings.TrimPrefix(versionFile, dir+"/")
+	v, err := readVersionFile(versionFile, rel)
+	return v, rel, err
+}
+
+// readVersionFile reads the version from path, using name in errors.
+func readVersionFile(path, name string) (*version.Version, error) {
+	b, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 	v, err := parseVersion(string(b))
-	return v, maybeErr(err, "parsing version file %q", strings.TrimPrefix(versionFile, dir+"/"))
+	return v, maybeErr(err, "parsing version file %q", name)
 }
 
 func parseVersion(versionString string) (*version.Version, error) {
@@ -18,7 +18,7 @@ type versionFile struct {
 	path, version string
 }
 
-func TestGetCoreVersionFromVersionFile_ok(t *testing.T) {
+func TestVersionFileSearch_CoreVersion_ok(t *testing.T) {
 
 	cases := []struct {
 		desc  string
@@ -82,7 +82,7 @@ func TestGetCoreVersionFromVersionFile_ok(t *testing.T) {
 			// Setup.
 			dir := writeTmpFileTree(t, files)
 			// Run.
-			got, err := getCoreVersionFromVersionFile(dir)
+			got, _, err := VersionFileSearch{}.CoreVersion(dir)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -92,7 +92,7 @@ func TestGetCoreVersionFromVersionFile_ok(t *testing.T) {
 	}
 }
 
-func TestGetCoreVersionFromVersionFile_err(t *testing.T) {
+func TestVersionFileSearch_CoreVersion_err(t *testing.T) {
 
 	cases := []struct {
 		desc  string
@@ -121,7 +121,7 @@ func TestGetCoreVersionFromVersionFile_err(t *testing.T) {
 			// Setup.
 			dir := writeTmpFileTree(t, files)
 			// Run.
-			_, gotErr := getCoreVersionFromVersionFile(dir)
+			_, _, gotErr := VersionFileSearch{}.CoreVersion(dir)
 			// Assert.
 			if gotErr == nil {
 				t.Fatalf("got nil error; want error containing %q", want)
@@ -0,0 +1,259 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package crt
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/hashicorp/go-version"
+)
+
+// Names of the version strategies, as used in ParseVersionStrategy.
+const (
+	StrategyVersionFile = "version-file"
+	StrategyFile        = "file"
+	StrategyGitTag      = "git-tag"
+	StrategyGoConst     = "go-const"
+)
+
+// VersionStrategy determines the core version of a product from its source
+// code.
+type VersionStrategy interface {
+	// CoreVersion returns the core version of the product in dir, and
+	// where it was found.
+	CoreVersion(dir string) (*version.Version, VersionSource, error)
+}
+
+// VersionSource records where a product's core version came from.
+type VersionSource struct {
+	// Strategy is the name of the strategy that found the version.
+	Strategy string
+	// Location is the file, relative to the product directory, or the git
+	// tag the version was read from. It's empty if no version was found
+	// and the default was used.
+	Location string `json:",omitempty"`
+}
+
+func (s VersionSource) String() string {
+	if s.Location == "" {
+		return s.Strategy + " (default version)"
+	}
+	return fmt.Sprintf("%s %s", s.Strategy, s.Location)
+}
+
+// ParseVersionStrategy parses a version strategy spec, which is one of:
+//
+//   - "version-file" (or empty): search for a VERSION file in the usual places.
+//   - "file:PATH": read the version from the file at PATH.
+//   - "git-tag" or "git-tag:PREFIX": use the nearest semver tag reachable
+//     from HEAD, optionally only considering tags starting with PREFIX.
+//   - "go-const:FILE:NAME": use the string constant or variable NAME
+//     declared in the Go file FILE. NAME defaults to Version.
+//
+// Paths are relative to the product directory.
+func ParseVersionStrategy(spec string) (VersionStrategy, error) {
+	kind, arg, hasArg := strings.Cut(spec, ":")
+	switch kind {
+	case "", StrategyVersionFile:
+		if hasArg {
+			return nil, fmt.Errorf("version strategy %q takes no argument", StrategyVersionFile)
+		}
+		return VersionFileSearch{}, nil
+	case StrategyFile:
+		if arg == "" {
+			return nil, fmt.Errorf("version strategy %q needs a path, e.g. %q", StrategyFile, StrategyFile+":release/VERSION")
+		}
+		return VersionFile{Path: arg}, nil
+	case StrategyGitTag:
+		return GitTag{Prefix: arg}, nil
+	case StrategyGoConst:
+		file, name, _ := strings.Cut(arg, ":")
+		if file == "" {
+			return nil, fmt.Errorf("version strategy %q needs a Go file, e.g. %q", StrategyGoConst, StrategyGoConst+":version/version.go:Version")
+		}
+		if name == "" {
+			name = "Version"
+		}
+		return GoConst{File: file, Name: name}, nil
+	}
+	return nil, fmt.Errorf("unknown version strategy %q; want one of %s, %s, %s, or %s",
+		kind, StrategyVersionFile, StrategyFile, StrategyGitTag, StrategyGoConst)
+}
+
+// VersionFileSearch reads the version from the first VERSION file found in
+// the product directory, .release, version, or dev. If there's none, it
+// returns a default version.
+type VersionFileSearch struct{}
+
+func (VersionFileSearch) CoreVersion(dir string) (*version.Version, VersionSource, error) {
+	v, file, err := searchVersionFile(dir)
+	return v, VersionSource{Strategy: StrategyVersionFile, Location: file}, err
+}
+
+// VersionFile reads the version from a specific file.
+type VersionFile struct {
+	// Path is relative to the product directory, unless it's absolute.
+	Path string
+}
+
+func (f VersionFile) CoreVersion(dir string) (*version.Version, VersionSource, error) {
+	src := VersionSource{Strategy: StrategyFile, Location: f.Path}
+	path := f.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	v, err := readVersionFile(path, f.Path)
+	return v, src, err
+}
+
+// GitTag uses the nearest tag reachable from HEAD which is a semantic
+// version, once Prefix and any leading "v" are removed. If there are several
+// such tags on the nearest commit, the highest version wins.
+type GitTag struct {
+	Prefix string
+}
+
+func (g GitTag) CoreVersion(dir string) (*version.Version, VersionSource, error) {
+	src := VersionSource{Strategy: StrategyGitTag}
+	repo, err := gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, src, err
+	}
+	tagged, err := g.taggedVersions(repo)
+	if err != nil {
+		return nil, src, err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, src, err
+	}
+	commits, err := repo.Log(&gogit.LogOptions{From: head.Hash(), Order: gogit.LogOrderBSF})
+	if err != nil {
+		return nil, src, err
+	}
+	var best *taggedVersion
+	err = commits.ForEach(func(c *object.Commit) error {
+		for _, tv := range tagged[c.Hash] {
+			tv := tv
+			if best == nil || tv.version.GreaterThan(best.version) {
+				best = &tv
+			}
+		}
+		if best != nil {
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, src, err
+	}
+	if best == nil {
+		return nil, src, fmt.Errorf("no tag matching %q followed by a semantic version is reachable from HEAD "+
+			"(shallow clones may need their tags fetched)", g.Prefix)
+	}
+	src.Location = best.tag
+	return best.version, src, nil
+}
+
+type taggedVersion struct {
+	tag     string
+	version *version.Version
+}
+
+// taggedVersions returns the versions of all matching tags, keyed by the
+// commit they point to.
+func (g GitTag) taggedVersions(repo *gogit.Repository) (map[plumbing.Hash][]taggedVersion, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	out := map[plumbing.Hash][]taggedVersion{}
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		vs, ok := strings.CutPrefix(name, g.Prefix)
+		if !ok {
+			return nil
+		}
+		v, err := parseVersion(strings.TrimPrefix(vs, "v"))
+		if err != nil {
+			// Not a version tag.
+			return nil
+		}
+		hash := ref.Hash()
+		// Annotated tags point to a tag object rather than a commit.
+		if t, err := repo.TagObject(hash); err == nil {
+			c, err := t.Commit()
+			if err != nil {
+				return nil
+			}
+			hash = c.Hash
+		}
+		out[hash] = append(out[hash], taggedVersion{tag: name, version: v})
+		return nil
+	})
+	return out, err
+}
+
+// GoConst reads the version from a string constant or variable declared at
+// package level in a Go file, such as Version in version/version.go.
+type GoConst struct {
+	// File is relative to the product directory, unless it's absolute.
+	File string
+	Name string
+}
+
+func (g GoConst) CoreVersion(dir string) (*version.Version, VersionSource, error) {
+	src := VersionSource{Strategy: StrategyGoConst, Location: g.File + ":" + g.Name}
+	path := g.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, src, err
+	}
+	value, err := g.findString(f)
+	if err != nil {
+		return nil, src, fmt.Errorf("reading %s from %q: %w", g.Name, g.File, err)
+	}
+	v, err := parseVersion(value)
+	return v, src, maybeErr(err, "parsing %s in %q", g.Name, g.File)
+}
+
+func (g GoConst) findString(f *ast.File) (string, error) {
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || (gd.Tok != token.CONST && gd.Tok != token.VAR) {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, name := range vs.Names {
+				if name.Name != g.Name {
+					continue
+				}
+				if i >= len(vs.Values) {
+					return "", errors.New("it has no value")
+				}
+				lit, ok := vs.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return "", errors.New("its value is not a string literal")
+				}
+				return strconv.Unquote(lit.Value)
+			}
+		}
+	}
+	return "", errors.New("not declared")
+}
@@ -0,0 +1,172 @@
+// Copyright IBM Corp. 2022, 2025
+// SPDX-License-Identifier: MPL-2.0
+
+package crt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const versionDotGo = `package version
+
+import "fmt"
+
+const (
+	Name    = "lockbox"
+	Version = "1.4.0-beta.1"
+)
+
+var Full = fmt.Sprintf("%s v%s", Name, Version)
+`
+
+func TestVersionStrategy_CoreVersion_ok(t *testing.T) {
+	cases := []struct {
+		spec         string
+		files        []versionFile
+		want         string
+		wantLocation string
+	}{
+		{"", []versionFile{{".release/VERSION", "1.2.3"}}, "1.2.3", ".release/VERSION"},
+		{"version-file", nil, "0.0.0-version-file-missing", ""},
+		{"file:build/version.txt", []versionFile{{"build/version.txt", "2.0.0\n"}}, "2.0.0", "build/version.txt"},
+		{"go-const:version/version.go", []versionFile{{"version/version.go", versionDotGo}}, "1.4.0-beta.1", "version/version.go:Version"},
+		{"go-const:version/version.go:Version", []versionFile{{"version/version.go", versionDotGo}}, "1.4.0-beta.1", "version/version.go:Version"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.spec, func(t *testing.T) {
+			dir := writeTmpFileTree(t, c.files)
+			s, err := ParseVersionStrategy(c.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, src, err := s.CoreVersion(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != c.want {
+				t.Errorf("got version %q; want %q", got, c.want)
+			}
+			if src.Location != c.wantLocation {
+				t.Errorf("got location %q; want %q", src.Location, c.wantLocation)
+			}
+		})
+	}
+}
+
+func TestVersionStrategy_CoreVersion_err(t *testing.T) {
+	cases := []struct {
+		spec    string
+		files   []versionFile
+		wantErr string
+	}{
+		{"file:VERSION", nil, "no such file or directory"},
+		{"file:VERSION", []versionFile{{"VERSION", "1.2.3+ent"}}, `parsing version file "VERSION": version "1.2.3+ent" contains metadata`},
+		{"go-const:version.go:Missing", []versionFile{{"version.go", versionDotGo}}, `reading Missing from "version.go": not declared`},
+		{"go-const:version.go:Full", []versionFile{{"version.go", versionDotGo}}, `reading Full from "version.go": its value is not a string literal`},
+		{"git-tag", nil, "repository does not exist"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.spec, func(t *testing.T) {
+			dir := writeTmpFileTree(t, c.files)
+			s, err := ParseVersionStrategy(c.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _, err = s.CoreVersion(dir)
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Fatalf("got error %v; want error containing %q", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseVersionStrategy_err(t *testing.T) {
+	cases := map[string]string{
+		"file":           `version strategy "file" needs a path`,
+		"go-const":       `version strategy "go-const" needs a Go file`,
+		"version-file:x": `version strategy "version-file" takes no argument`,
+		"calver":         `unknown version strategy "calver"`,
+	}
+	for spec, want := range cases {
+		spec, want := spec, want
+		t.Run(spec, func(t *testing.T) {
+			_, err := ParseVersionStrategy(spec)
+			if err == nil || !strings.HasPrefix(err.Error(), want) {
+				t.Fatalf("got error %v; want error starting %q", err, want)
+			}
+		})
+	}
+}
+
+func TestGitTag_CoreVersion(t *testing.T) {
+	dir := writeTmpFileTree(t, nil)
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func() plumbing.Hash {
+		h, err := wt.Commit("commit", &gogit.CommitOptions{
+			AllowEmptyCommits: true,
+			Author:            &object.Signature{Name: "test", Email: "test@test.com", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	tag := func(name string, h plumbing.Hash, annotated bool) {
+		var opts *gogit.CreateTagOptions
+		if annotated {
+			opts = &gogit.CreateTagOptions{Message: name, Tagger: &object.Signature{Name: "test", Email: "test@test.com", When: time.Now()}}
+		}
+		if _, err := repo.CreateTag(name, h, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first := commit()
+	tag("v1.0.0", first, false)
+	tag("tools/v3.0.0", first, false)
+	second := commit()
+	tag("v1.1.0", second, true)
+	tag("v1.1.0-rc.1", second, false)
+	tag("release-candidate", second, false)
+	commit()
+
+	cases := []struct {
+		spec, want, wantTag string
+	}{
+		{"git-tag", "1.1.0", "v1.1.0"},
+		{"git-tag:tools/", "3.0.0", "tools/v3.0.0"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.spec, func(t *testing.T) {
+			s, err := ParseVersionStrategy(c.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, src, err := s.CoreVersion(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != c.want {
+				t.Errorf("got version %q; want %q", got, c.want)
+			}
+			if src.Location != c.wantTag {
+				t.Errorf("got tag %q; want %q", src.Location, c.wantTag)
+			}
+		})
+	}
+}